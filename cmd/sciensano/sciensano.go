@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"github.com/clambin/go-common/http/roundtripper"
 	"github.com/clambin/go-common/taskmanager"
 	"github.com/clambin/go-common/taskmanager/httpserver"
 	promserver "github.com/clambin/go-common/taskmanager/prometheus"
 	gjson "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/population"
 	"github.com/clambin/sciensano/v2/internal/reports"
 	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
 	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/server"
 	"github.com/prometheus/client_golang/prometheus"
 	"log/slog"
@@ -20,6 +24,9 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -30,6 +37,21 @@ var (
 	simpleJSONAddr   = flag.String("addr", ":8080", "Server address")
 	prometheusAddr   = flag.String("prometheus", ":9090", "Prometheus metrics port")
 	demographicsPath = flag.String("demographics", "/data/population/TF_SOC_POP_STRUCT_2023.txt", "Path of the demographics file")
+	eventsPath       = flag.String("events", "", "Path of a YAML file with events (e.g. lockdowns) to show as Grafana annotations")
+	localSource      = flag.String("local", "", "Read the Sciensano feeds from a local directory or tarball instead of the Sciensano API")
+	cacheDir         = flag.String("cache", "", "Directory to cache the Sciensano feeds in, so the server can start with the previously retrieved data")
+	maxAttempts      = flag.Int("max-attempts", datasource.DefaultRetryPolicy.MaxAttempts, "Maximum number of attempts to fetch a Sciensano feed during one polling interval")
+	staleness        = flag.Duration("staleness", 0, "Report /health as degraded (503) when a feed hasn't been updated for longer than this duration. Default is no check")
+	positivityWindow = flag.Int("positivity-window", reports.DefaultOptions.PositivityWindow, "Number of days over which the test positivity rate is calculated")
+	rtWindow         = flag.Int("rt-window", reports.DefaultOptions.Rt.Window, "Number of days over which Rt is estimated")
+	rtSIMean         = flag.Float64("rt-si-mean", reports.DefaultOptions.Rt.SerialIntervalMean, "Mean of the serial interval used to estimate Rt, in days")
+	rtSISD           = flag.Float64("rt-si-sd", reports.DefaultOptions.Rt.SerialIntervalSD, "Standard deviation of the serial interval used to estimate Rt, in days")
+	growthWindow     = flag.Int("growth-window", reports.DefaultOptions.GrowthWindow, "Number of days over which growth rates and doubling times are calculated")
+	fatalityLag      = flag.Int("fatality-lag", reports.DefaultOptions.FatalityLag, "Number of days between a case and a resulting death, used by the case fatality ratio")
+	hospitalLag      = flag.Int("hospitalisation-lag", reports.DefaultOptions.HospitalisationLag, "Number of days between a case and a resulting hospital admission, used by the hospitalisation ratio")
+	ratioWindow      = flag.Int("ratio-window", reports.DefaultOptions.RatioWindow, "Number of days over which the case fatality and hospitalisation ratios are calculated")
+	configFile       = flag.String("config", "", "Configuration file listing the reports to generate. Default is the built-in configuration")
+	feedFormats      = flag.String("formats", "", "Comma-separated list of feed formats per endpoint (e.g. vaccinations=csv,cases=csv). Default is json")
 )
 
 func main() {
@@ -43,9 +65,24 @@ func main() {
 
 	logger.Info("Sciensano API server starting", "version", version)
 
-	popStore := population.Server{Path: *demographicsPath, Interval: 24 * time.Hour, Logger: logger.With("component", "population")}
+	formats, err := parseFormats(*feedFormats)
+	if err != nil {
+		logger.Error("invalid formats", "err", err)
+		os.Exit(1)
+	}
 
-	reportsStore := store.Store{Logger: logger.With("component", "reportsStore")}
+	if err = reportOptions().Validate(); err != nil {
+		logger.Error("invalid report options", "err", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		logger.Error("invalid configuration", "err", err)
+		os.Exit(1)
+	}
+
+	popStore := population.Server{Path: demographics(cfg), Interval: 24 * time.Hour, Logger: logger.With("component", "population")}
 
 	httpMetrics := roundtripper.NewDefaultRoundTripMetrics("sciensano", "", "sciensano")
 	prometheus.MustRegister(httpMetrics)
@@ -53,33 +90,314 @@ func main() {
 		roundtripper.WithLimiter(3),
 		roundtripper.WithInstrumentedRoundTripper(httpMetrics),
 	)
-	client := &http.Client{Transport: r}
-
-	ds := datasource.NewSciensanoDatastore("", 15*time.Minute, client, logger.With("component", "datasource"))
-	reporters := reports.NewSciensanoReporters(ds, &reportsStore, &popStore, logger.With("component", "reporters"))
-
-	var tasks []taskmanager.Task
-	tasks = append(tasks, ds)
-	tasks = append(tasks, &popStore)
-	tasks = append(tasks, reporters...)
 
+	dsMetrics := datasource.NewMetrics("sciensano", "")
+	prometheus.MustRegister(dsMetrics)
 	gjsonMetrics := gjson.NewDefaultPrometheusQueryMetrics("sciensano", "", "sciensano")
 	prometheus.MustRegister(gjsonMetrics)
-	s := server.New(&reportsStore, gjsonMetrics, logger.With("component", "server"))
 
-	tasks = append(
-		tasks, promserver.New(promserver.WithAddr(*prometheusAddr)),
-		s,
-		httpserver.New(*simpleJSONAddr, s.JSONServer),
+	reportsStore := store.Store{Logger: logger.With("component", "reportsStore")}
+	prometheus.MustRegister(store.NewCollector(&reportsStore, "sciensano", ""))
+
+	a := application{
+		formats:      formats,
+		client:       &http.Client{Transport: r},
+		reportsStore: &reportsStore,
+		popStore:     &popStore,
+		updates:      &datasource.UpdateLog{},
+		dsMetrics:    dsMetrics,
+		gjsonMetrics: gjsonMetrics,
+		logger:       logger,
+	}
+
+	var handler switchingHandler
+	tm := taskmanager.New(
+		&popStore,
+		promserver.New(promserver.WithAddr(*prometheusAddr)),
+		httpserver.New(*simpleJSONAddr, &handler),
 		httpserver.New(":6060", http.DefaultServeMux),
 	)
-	tm := taskmanager.New(tasks...)
 
 	ctx, done := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer done()
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	current, err := a.configure(cfg, &handler, nil)
+	if err != nil {
+		logger.Error("failed to create reports", "err", err)
+		os.Exit(1)
+	}
+
+	ch := make(chan error, 1)
+	go func() { ch <- tm.Run(ctx) }()
 
-	if err := tm.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+	if err = a.run(ctx, current, &handler, reload, ch); err != nil && !errors.Is(err, context.Canceled) {
 		logger.Error("failed to start", "err", err)
 		os.Exit(1)
 	}
 }
+
+// application holds the components that are shared by all configurations.
+type application struct {
+	formats      map[sciensano.Endpoint]sciensano.Format
+	client       *http.Client
+	reportsStore *store.Store
+	popStore     *population.Server
+	updates      *datasource.UpdateLog
+	dsMetrics    *datasource.Metrics
+	gjsonMetrics gjson.PrometheusQueryMetrics
+	logger       *slog.Logger
+}
+
+// configuration holds the tasks that depend on the configuration: the sources, and the reporters and Grafana server that use them.
+type configuration struct {
+	sources *sources
+	tasks   []taskmanager.Task
+	keys    []string
+}
+
+// sources are the datasources and the records they publish. A reload only replaces them when the polling interval changes:
+// otherwise the datasources keep their data (and the validators of their last fetch), so a reload doesn't download every feed again.
+type sources struct {
+	pollingInterval time.Duration
+	datasources     *datasource.SciensanoSources
+	records         *reports.Records
+}
+
+func (s *sources) Run(ctx context.Context) error {
+	return taskmanager.New(s.datasources, s.records).Run(ctx)
+}
+
+// run runs the tasks that depend on the configuration. On SIGHUP, it re-reads the configuration and restarts the
+// reporters and the Grafana server with the new configuration. The sources are only restarted if they changed.
+// done receives the result of the tasks that keep running across configurations.
+func (a *application) run(ctx context.Context, current configuration, handler *switchingHandler, reload <-chan os.Signal, done <-chan error) error {
+	sourceTasks := startTasks(ctx, current.sources)
+	for {
+		reportTasks := startTasks(ctx, current.tasks...)
+		next, err := a.waitForReload(ctx, current, handler, reload, sourceTasks, reportTasks, done)
+		reportTasks.stop()
+		if next == nil {
+			sourceTasks.stop()
+			return err
+		}
+		// the old reporters have stopped: remove the reports that the new configuration no longer creates
+		a.reportsStore.Retain(next.keys)
+		if next.sources != current.sources {
+			sourceTasks.stop()
+			sourceTasks = startTasks(ctx, next.sources)
+		}
+		a.logger.Info("configuration reloaded", "sourcesRestarted", next.sources != current.sources)
+		current = *next
+	}
+}
+
+// waitForReload returns the configuration to run when a valid configuration is loaded. If the tasks of the current
+// configuration or any other tasks stop, it returns nil and the error.
+func (a *application) waitForReload(ctx context.Context, current configuration, handler *switchingHandler, reload <-chan os.Signal, sourceTasks, reportTasks *tasks, done <-chan error) (*configuration, error) {
+	for {
+		select {
+		case <-reload:
+			cfg, err := loadConfig(*configFile)
+			if err != nil {
+				a.logger.Error("failed to reload configuration. keeping current configuration", "err", err)
+				continue
+			}
+			next, err := a.configure(cfg, handler, current.sources)
+			if err != nil {
+				a.logger.Error("failed to reload configuration. keeping current configuration", "err", err)
+				continue
+			}
+			if err = a.popStore.Reload(demographics(cfg)); err != nil {
+				a.logger.Error("failed to reload demographics. keeping current demographics", "err", err)
+			}
+			return &next, nil
+		case <-sourceTasks.done:
+			return nil, a.stopped(ctx, sourceTasks, done)
+		case <-reportTasks.done:
+			return nil, a.stopped(ctx, reportTasks, done)
+		case err := <-done:
+			return nil, err
+		}
+	}
+}
+
+// stopped returns the error of tasks that stopped. If the context was canceled, it returns the result of the tasks
+// that run across configurations instead.
+func (a *application) stopped(ctx context.Context, t *tasks, done <-chan error) error {
+	if ctx.Err() != nil {
+		return <-done
+	}
+	return t.err
+}
+
+// reportOptions returns the options of the reporters, as set by the command line flags
+func reportOptions() reports.Options {
+	return reports.Options{
+		PositivityWindow:   *positivityWindow,
+		Rt:                 reporter.RtParameters{Window: *rtWindow, SerialIntervalMean: *rtSIMean, SerialIntervalSD: *rtSISD},
+		GrowthWindow:       *growthWindow,
+		FatalityLag:        *fatalityLag,
+		HospitalisationLag: *hospitalLag,
+		RatioWindow:        *ratioWindow,
+	}
+}
+
+// configure creates the tasks for the configuration. It reuses the current sources, unless their configuration changed.
+// The Grafana server only replaces the server behind handler when its task starts.
+func (a *application) configure(cfg config.Config, handler *switchingHandler, current *sources) (configuration, error) {
+	pollingInterval := cfg.PollingInterval
+	if pollingInterval == 0 {
+		pollingInterval = defaultPollingInterval
+	}
+
+	src := current
+	if src == nil || src.pollingInterval != pollingInterval {
+		var err error
+		if src, err = a.newSources(pollingInterval); err != nil {
+			return configuration{}, err
+		}
+	}
+
+	reporters := reports.NewSciensanoReporters(src.datasources, a.reportsStore, a.popStore, cfg, reportOptions(), a.logger.With("component", "reporters"))
+
+	s := server.New(a.reportsStore, cfg, a.gjsonMetrics, a.logger.With("component", "server"))
+	s.Sources = src.datasources
+	s.StalenessThreshold = *staleness
+	s.Population = a.popStore
+	s.ExpectedReports = reports.ReportNames(reporters)
+	s.Updates = a.updates
+	s.Records = src.records
+	if path := events(cfg); path != "" {
+		var err error
+		if s.Events, err = server.LoadEvents(path); err != nil {
+			return configuration{}, err
+		}
+	}
+
+	return configuration{
+		sources: src,
+		keys:    cfg.Keys(),
+		tasks:   append(reporters, &handlerTask{handler: handler, server: s}),
+	}, nil
+}
+
+func (a *application) newSources(pollingInterval time.Duration) (*sources, error) {
+	var ds *datasource.SciensanoSources
+	if *localSource != "" {
+		var err error
+		if ds, err = datasource.NewLocalSciensanoDatastore(*localSource, a.formats, pollingInterval, a.logger.With("component", "datasource")); err != nil {
+			return nil, fmt.Errorf("local datasource: %w", err)
+		}
+	} else {
+		ds = datasource.NewSciensanoDatastore("", a.formats, pollingInterval, a.client, a.logger.With("component", "datasource"))
+	}
+	retryPolicy := datasource.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = *maxAttempts
+	ds.SetRetryPolicy(retryPolicy)
+	ds.SetMetrics(a.dsMetrics)
+	ds.SetUpdateLog(a.updates)
+	if *cacheDir != "" {
+		ds.SetCacheDirectory(*cacheDir)
+	}
+	return &sources{pollingInterval: pollingInterval, datasources: ds, records: reports.NewRecords(ds)}, nil
+}
+
+// tasks runs a group of tasks until they are stopped.
+type tasks struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func startTasks(ctx context.Context, t ...taskmanager.Task) *tasks {
+	ctx, cancel := context.WithCancel(ctx)
+	g := tasks{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(g.done)
+		g.err = taskmanager.New(t...).Run(ctx)
+	}()
+	return &g
+}
+
+// stop stops the tasks and waits for them to return.
+func (t *tasks) stop() {
+	t.cancel()
+	<-t.done
+}
+
+const defaultPollingInterval = 15 * time.Minute
+
+func loadConfig(path string) (config.Config, error) {
+	if path == "" {
+		return config.Default(), nil
+	}
+	return config.Load(path)
+}
+
+func demographics(cfg config.Config) string {
+	if cfg.Demographics != "" {
+		return cfg.Demographics
+	}
+	return *demographicsPath
+}
+
+func events(cfg config.Config) string {
+	if cfg.Events != "" {
+		return cfg.Events
+	}
+	return *eventsPath
+}
+
+// switchingHandler forwards requests to the current handler, so the handler can be replaced without restarting the HTTP server.
+type switchingHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func (h *switchingHandler) Set(handler http.Handler) {
+	h.handler.Store(&handler)
+}
+
+func (h *switchingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := h.handler.Load()
+	if handler == nil {
+		http.Error(w, "server starting", http.StatusServiceUnavailable)
+		return
+	}
+	(*handler).ServeHTTP(w, r)
+}
+
+// handlerTask serves the Grafana server through the switchingHandler while it runs.
+type handlerTask struct {
+	handler *switchingHandler
+	server  *server.Server
+}
+
+func (t *handlerTask) Run(ctx context.Context) error {
+	t.handler.Set(t.server)
+	return t.server.Run(ctx)
+}
+
+func parseFormats(value string) (map[sciensano.Endpoint]sciensano.Format, error) {
+	formats := make(map[sciensano.Endpoint]sciensano.Format)
+	if value == "" {
+		return formats, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		endpointName, formatName, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid format specification: %q", entry)
+		}
+		endpoint, ok := sciensano.EndpointNames[strings.TrimSpace(endpointName)]
+		if !ok {
+			return nil, fmt.Errorf("invalid endpoint: %q", endpointName)
+		}
+		format, ok := sciensano.FormatNames[strings.TrimSpace(formatName)]
+		if !ok {
+			return nil, fmt.Errorf("invalid format: %q", formatName)
+		}
+		formats[endpoint] = format
+	}
+	return formats, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"github.com/clambin/sciensano/v2/internal/population"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestApplication_Run(t *testing.T) {
+	dir := t.TempDir()
+	for name, endpoint := range sciensano.EndpointNames {
+		content, err := os.ReadFile(filepath.Join("..", "..", "internal", "sciensano", "testutil", "testdata", name+".json"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, sciensano.MustGetFilename(endpoint, sciensano.JSON)), content, 0o644))
+	}
+	cfgPath := filepath.Join(dir, "config.yaml")
+	writeConfig := func(cfg string) { require.NoError(t, os.WriteFile(cfgPath, []byte(cfg), 0o644)) }
+	setFlag(t, localSource, dir)
+	setFlag(t, configFile, cfgPath)
+
+	writeConfig(`
+pollingInterval: 1h
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total]
+  - name: rt
+    type: rt
+    modes: [Total, ByRegion]
+`)
+	reportsStore := store.Store{Logger: slog.Default()}
+	a := application{
+		formats:      map[sciensano.Endpoint]sciensano.Format{},
+		reportsStore: &reportsStore,
+		popStore:     &population.Server{Logger: slog.Default()},
+		updates:      &datasource.UpdateLog{},
+		logger:       slog.Default(),
+	}
+	var handler switchingHandler
+	cfg, err := loadConfig(*configFile)
+	require.NoError(t, err)
+	current, err := a.configure(cfg, &handler, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reload := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		done <- nil
+	}()
+	errCh := make(chan error)
+	go func() { errCh <- a.run(ctx, current, &handler, reload, done) }()
+
+	waitForReport(t, &reportsStore, "cases-Total")
+	s := nextServer(t, &handler, nil)
+	sources := s.Sources
+
+	// reload with the same polling interval: the reporters restart and get the data of the current sources right away
+	writeConfig(`
+pollingInterval: 1h
+reports:
+  - name: cases2
+    datasource: cases
+    modes: [Total]
+`)
+	reload <- syscall.SIGHUP
+	waitForReport(t, &reportsStore, "cases2-Total")
+	s = nextServer(t, &handler, s)
+	assert.Same(t, sources, s.Sources)
+	// reports that are no longer configured are removed
+	assert.NotContains(t, reportsStore.Keys(), "cases-Total")
+	assert.NotContains(t, reportsStore.Keys(), "rt-ByRegion")
+
+	// a new polling interval restarts the sources
+	writeConfig(`
+pollingInterval: 2h
+reports:
+  - name: cases3
+    datasource: cases
+    modes: [Total]
+`)
+	reload <- syscall.SIGHUP
+	waitForReport(t, &reportsStore, "cases3-Total")
+	s = nextServer(t, &handler, s)
+	assert.NotSame(t, sources, s.Sources)
+	assert.NotContains(t, reportsStore.Keys(), "cases2-Total")
+
+	cancel()
+	select {
+	case err = <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("application did not stop")
+	}
+}
+
+func setFlag[T any](t *testing.T, flag *T, value T) {
+	t.Helper()
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+func waitForReport(t *testing.T, s *store.Store, name string) {
+	t.Helper()
+	require.Eventually(t, func() bool { return slices.Contains(s.Keys(), name) }, 10*time.Second, 10*time.Millisecond)
+}
+
+// nextServer waits for the handler to serve a server other than previous, and returns it.
+func nextServer(t *testing.T, h *switchingHandler, previous *server.Server) *server.Server {
+	t.Helper()
+	var s *server.Server
+	require.Eventually(t, func() bool {
+		if handler := h.handler.Load(); handler != nil {
+			s, _ = (*handler).(*server.Server)
+		}
+		return s != nil && s != previous
+	}, time.Second, 10*time.Millisecond)
+	return s
+}
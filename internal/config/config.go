@@ -0,0 +1,411 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/clambin/go-common/set"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// Config lists the reports to generate. Each report is also offered as a Grafana metric. Reports with the same name
+// are offered as one metric.
+type Config struct {
+	// PollingInterval is the interval at which the Sciensano feeds are polled. If zero, the default interval is used.
+	PollingInterval time.Duration `yaml:"pollingInterval"`
+	// Demographics is the path of the demographics file. If empty, the path from the command line is used.
+	Demographics string `yaml:"demographics"`
+	// Events is the path of the file with the events that are shown as Grafana annotations. If empty, the path from the command line is used.
+	Events  string   `yaml:"events"`
+	Reports []Report `yaml:"reports"`
+}
+
+// Report configures one or more reports of the same type.
+type Report struct {
+	// Name is the name of the Grafana metric and the prefix of the keys under which the reports are stored
+	Name string `yaml:"name"`
+	// Type is the type of report. Default is a summary of Datasource.
+	Type ReportType `yaml:"type"`
+	// Datasource is the name of the Sciensano endpoint to summarize. Only valid for summary reports.
+	Datasource string `yaml:"datasource"`
+	// Modes are the summary modes for which to generate a report
+	Modes []string `yaml:"modes"`
+	// DoseTypes are the dose types for which to generate a report. Only valid for vaccination-rate reports.
+	DoseTypes []string `yaml:"doseTypes"`
+	// Transforms are the transforms to apply to a summary. Default is the absolute figures. Only valid for summary reports.
+	Transforms []Transform `yaml:"transforms"`
+}
+
+// ReportType determines which report is generated
+type ReportType string
+
+const (
+	SummaryReport              ReportType = "summary"
+	VaccinationRateReport      ReportType = "vaccination-rate"
+	PositivityRateReport       ReportType = "positivity-rate"
+	RtReport                   ReportType = "rt"
+	CaseFatalityRatioReport    ReportType = "case-fatality-ratio"
+	HospitalisationRatioReport ReportType = "hospitalisation-ratio"
+)
+
+// Transform is applied to a summary. The name of the transform is appended to the store key, except for Absolute.
+type Transform string
+
+const (
+	Absolute     Transform = "absolute"
+	Per100k      Transform = "per100k"
+	Per100k14d   Transform = "per100k-14d"
+	Growth       Transform = "growth"
+	DoublingTime Transform = "doubling-time"
+	PerHospital  Transform = "per-hospital"
+)
+
+// IsTrend reports whether the transform shows how the figures change over time, rather than rescaling them
+func (t Transform) IsTrend() bool {
+	return t == Growth || t == DoublingTime
+}
+
+//go:embed default.yaml
+var defaultConfig []byte
+
+// Default returns the configuration of the reports that are generated if no configuration file is provided.
+func Default() Config {
+	cfg, err := Parse(bytes.NewReader(defaultConfig))
+	if err != nil {
+		panic(fmt.Errorf("default config: %w", err))
+	}
+	return cfg
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	cfg, err := Parse(f)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse reads and validates a configuration.
+func Parse(r io.Reader) (Config, error) {
+	var cfg Config
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("invalid yaml: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration only contains reports that can be generated.
+func (c Config) Validate() error {
+	var errs []error
+	if c.PollingInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid polling interval: %s", c.PollingInterval))
+	}
+	for index, report := range c.Reports {
+		if err := report.validate(); err != nil {
+			name := report.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", index+1)
+			}
+			errs = append(errs, fmt.Errorf("report %s: %w", name, err))
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, c.validateMetrics(), c.validateKeys())
+	}
+	return errors.Join(errs...)
+}
+
+// validateMetrics checks that reports offered as the same Grafana metric can be combined.
+func (c Config) validateMetrics() error {
+	reports := make(map[string]Report)
+	for _, report := range c.Reports {
+		other, ok := reports[report.Name]
+		if !ok {
+			reports[report.Name] = report
+			continue
+		}
+		if report.GetType() != other.GetType() || report.Datasource != other.Datasource {
+			return fmt.Errorf("report %s: reports with the same name must have the same type and datasource", report.Name)
+		}
+	}
+	return nil
+}
+
+// validateKeys checks that no two reports are stored under the same key.
+func (c Config) validateKeys() error {
+	keys := set.Create[string]()
+	for _, key := range c.Keys() {
+		if keys.Contains(key) {
+			return fmt.Errorf("duplicate report: %s", key)
+		}
+		keys.Add(key)
+	}
+	return nil
+}
+
+// populationModes are the summary modes for which population figures are available
+var populationModes = set.Create(sciensano.ByRegion, sciensano.ByProvince, sciensano.ByAgeGroup, sciensano.ByGender)
+
+func (r Report) validate() error {
+	if r.Name == "" {
+		return errors.New("missing name")
+	}
+	if len(r.Modes) == 0 {
+		return errors.New("missing modes")
+	}
+	if r.Type != SummaryReport && r.Type != "" && (r.Datasource != "" || len(r.Transforms) > 0) {
+		return fmt.Errorf("datasource and transforms are only valid for summary reports")
+	}
+	if r.Type != VaccinationRateReport && len(r.DoseTypes) > 0 {
+		return fmt.Errorf("doseTypes are only valid for vaccination-rate reports")
+	}
+
+	var validModes set.Set[sciensano.SummaryColumn]
+	switch r.Type {
+	case SummaryReport, "":
+		endpoint, ok := sciensano.EndpointNames[r.Datasource]
+		if !ok {
+			return fmt.Errorf("invalid datasource: %q", r.Datasource)
+		}
+		validModes = ValidSummaryModes(endpoint)
+		for _, transform := range r.Transforms {
+			switch transform {
+			case Absolute:
+			case Growth, DoublingTime:
+				// the growth of hospitalisations is calculated from the admissions, which aren't broken down by category
+				if endpoint == sciensano.HospitalisationsEndpoint {
+					validModes.Remove(sciensano.ByCategory)
+				}
+			case Per100k, Per100k14d:
+				validModes = set.Intersection(validModes, populationModes)
+			case PerHospital:
+				if endpoint != sciensano.HospitalisationsEndpoint {
+					return fmt.Errorf("transform %s is only valid for %s", transform, sciensano.HospitalisationsEndpoint)
+				}
+			default:
+				return fmt.Errorf("invalid transform: %q", transform)
+			}
+		}
+	case VaccinationRateReport:
+		validModes = set.Intersection(sciensano.VaccinationsValidSummaryModes(), populationModes)
+		if len(r.DoseTypes) == 0 {
+			return errors.New("missing doseTypes")
+		}
+		for _, doseType := range r.DoseTypes {
+			if _, ok := sciensano.DoseTypeNames[doseType]; !ok {
+				return fmt.Errorf("invalid dose type: %q", doseType)
+			}
+		}
+	case PositivityRateReport:
+		validModes = sciensano.TestResultsValidSummaryModes()
+		validModes.Remove(sciensano.ByCategory)
+	case RtReport:
+		validModes = sciensano.CasesValidSummaryModes()
+	case CaseFatalityRatioReport:
+		validModes = set.Intersection(sciensano.MortalitiesValidSummaryModes(), sciensano.CasesValidSummaryModes())
+	case HospitalisationRatioReport:
+		// the hospitalisations feed has no age groups, so there is no ByAgeGroup mode
+		validModes = set.Intersection(sciensano.HospitalisationsValidSummaryModes(), sciensano.CasesValidSummaryModes())
+	default:
+		return fmt.Errorf("invalid type: %q", r.Type)
+	}
+
+	for _, mode := range r.Modes {
+		summaryColumn, ok := sciensano.SummaryColumnNames[mode]
+		if !ok || !validModes.Contains(summaryColumn) {
+			return fmt.Errorf("invalid mode: %q", mode)
+		}
+	}
+	return nil
+}
+
+// ValidSummaryModes returns the summary modes supported by an endpoint.
+func ValidSummaryModes(endpoint sciensano.Endpoint) set.Set[sciensano.SummaryColumn] {
+	switch endpoint {
+	case sciensano.CasesEndpoint:
+		return sciensano.CasesValidSummaryModes()
+	case sciensano.HospitalisationsEndpoint:
+		return sciensano.HospitalisationsValidSummaryModes()
+	case sciensano.MortalitiesEndpoint:
+		return sciensano.MortalitiesValidSummaryModes()
+	case sciensano.TestResultsEndpoint:
+		return sciensano.TestResultsValidSummaryModes()
+	case sciensano.VaccinationsEndpoint:
+		return sciensano.VaccinationsValidSummaryModes()
+	case sciensano.MunicipalityCasesEndpoint:
+		return sciensano.MunicipalityCasesValidSummaryModes()
+	default:
+		return set.Create[sciensano.SummaryColumn]()
+	}
+}
+
+// GetType returns the type of the report, defaulting to SummaryReport.
+func (r Report) GetType() ReportType {
+	if r.Type == "" {
+		return SummaryReport
+	}
+	return r.Type
+}
+
+// GetTransforms returns the transforms of the report, defaulting to Absolute.
+func (r Report) GetTransforms() []Transform {
+	if len(r.Transforms) == 0 {
+		return []Transform{Absolute}
+	}
+	return r.Transforms
+}
+
+// GetModes returns the report's summary modes. Validate ensures they are valid.
+func (r Report) GetModes() []sciensano.SummaryColumn {
+	modes := make([]sciensano.SummaryColumn, 0, len(r.Modes))
+	for _, mode := range r.Modes {
+		modes = append(modes, sciensano.SummaryColumnNames[mode])
+	}
+	return modes
+}
+
+// GetDoseTypes returns the report's dose types. Validate ensures they are valid.
+func (r Report) GetDoseTypes() []sciensano.DoseType {
+	doseTypes := make([]sciensano.DoseType, 0, len(r.DoseTypes))
+	for _, doseType := range r.DoseTypes {
+		doseTypes = append(doseTypes, sciensano.DoseTypeNames[doseType])
+	}
+	return doseTypes
+}
+
+// Key returns the key under which the report for a summary mode and transform is stored.
+func (r Report) Key(mode sciensano.SummaryColumn, transform Transform) string {
+	key := r.Name + "-" + mode.String()
+	if transform != Absolute && transform != "" {
+		key += "-" + string(transform)
+	}
+	return key
+}
+
+// DoseTypeKey returns the key under which a vaccination-rate report for a dose type and summary mode is stored.
+func (r Report) DoseTypeKey(doseType sciensano.DoseType, mode sciensano.SummaryColumn) string {
+	return r.Name + "-" + doseType.String() + "-" + mode.String()
+}
+
+// Keys returns the keys under which the configured reports are stored.
+func (c Config) Keys() []string {
+	var keys []string
+	for _, report := range c.Reports {
+		keys = append(keys, report.Keys()...)
+	}
+	return keys
+}
+
+// Keys returns the keys under which the report is stored.
+func (r Report) Keys() []string {
+	var keys []string
+	for _, mode := range r.GetModes() {
+		if r.GetType() == VaccinationRateReport {
+			for _, doseType := range r.GetDoseTypes() {
+				keys = append(keys, r.DoseTypeKey(doseType, mode))
+			}
+			continue
+		}
+		for _, transform := range r.GetTransforms() {
+			keys = append(keys, r.Key(mode, transform))
+		}
+	}
+	return keys
+}
+
+// Metric is a Grafana metric, combining all reports with the same name.
+type Metric struct {
+	Name       string
+	Type       ReportType
+	Datasource string
+	Modes      []sciensano.SummaryColumn
+	// Rates are the transforms offered for a summary report. Not every rate is generated for every mode: see SupportsTransform.
+	Rates     []Transform
+	DoseTypes []sciensano.DoseType
+	// Breakdowns are the summary columns that can be used as a secondary breakdown of a summary report
+	Breakdowns []sciensano.SummaryColumn
+	// ModeTransforms are the transforms that are generated for each mode. Only summary reports have transforms other than Absolute.
+	ModeTransforms map[sciensano.SummaryColumn][]Transform
+}
+
+// SupportsTransform reports whether a summary report is generated for the mode and transform. An empty transform means Absolute.
+func (m Metric) SupportsTransform(mode sciensano.SummaryColumn, transform Transform) bool {
+	if transform == "" {
+		transform = Absolute
+	}
+	return slices.Contains(m.ModeTransforms[mode], transform)
+}
+
+// Metrics returns the Grafana metrics for the configured reports, in order of appearance.
+func (c Config) Metrics() []Metric {
+	var metrics []Metric
+	for _, report := range c.Reports {
+		index := slices.IndexFunc(metrics, func(metric Metric) bool { return metric.Name == report.Name })
+		if index == -1 {
+			metrics = append(metrics, Metric{Name: report.Name, Type: report.GetType(), Datasource: report.Datasource, Breakdowns: report.secondaryBreakdowns()})
+			index = len(metrics) - 1
+		}
+		metric := &metrics[index]
+		metric.Modes = appendUnique(metric.Modes, report.GetModes()...)
+		metric.DoseTypes = appendUnique(metric.DoseTypes, report.GetDoseTypes()...)
+		if report.GetType() == SummaryReport {
+			metric.Rates = appendUnique(metric.Rates, report.GetTransforms()...)
+		}
+		if report.GetType() != VaccinationRateReport {
+			if metric.ModeTransforms == nil {
+				metric.ModeTransforms = make(map[sciensano.SummaryColumn][]Transform)
+			}
+			for _, mode := range report.GetModes() {
+				metric.ModeTransforms[mode] = appendUnique(metric.ModeTransforms[mode], report.GetTransforms()...)
+			}
+		}
+	}
+	for index := range metrics {
+		// a metric that only offers the absolute figures doesn't need a rate option
+		if len(metrics[index].Rates) == 1 && metrics[index].Rates[0] == Absolute {
+			metrics[index].Rates = nil
+		}
+	}
+	return metrics
+}
+
+// secondaryBreakdowns returns the summary columns that can be used as a secondary breakdown of the report's datasource, in order.
+func (r Report) secondaryBreakdowns() []sciensano.SummaryColumn {
+	if r.Datasource == "" {
+		return nil
+	}
+	validModes := ValidSummaryModes(sciensano.EndpointNames[r.Datasource])
+	var breakdowns []sciensano.SummaryColumn
+	for _, mode := range sciensano.SecondaryBreakdownModes().ListOrdered() {
+		if validModes.Contains(mode) {
+			breakdowns = append(breakdowns, mode)
+		}
+	}
+	return breakdowns
+}
+
+func appendUnique[T comparable](values []T, newValues ...T) []T {
+	for _, value := range newValues {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
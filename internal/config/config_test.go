@@ -0,0 +1,340 @@
+package config_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := config.Default()
+	require.NoError(t, cfg.Validate())
+
+	metrics := cfg.Metrics()
+	var names []string
+	for _, metric := range metrics {
+		names = append(names, metric.Name)
+	}
+	assert.Equal(t, []string{
+		"cases", "hospitalisations", "mortalities", "tests", "vaccinations", "municipality-cases",
+		"vaccination-rate", "positivity-rate", "rt", "case-fatality-ratio", "hospitalisation-ratio",
+	}, names)
+
+	assert.Equal(t, config.Metric{
+		Name:       "hospitalisations",
+		Type:       config.SummaryReport,
+		Datasource: "hospitalisations",
+		Modes:      []sciensano.SummaryColumn{sciensano.Total, sciensano.ByProvince, sciensano.ByRegion, sciensano.ByCategory},
+		Rates:      []config.Transform{config.Absolute, config.PerHospital, config.Growth, config.DoublingTime, config.Per100k, config.Per100k14d},
+		Breakdowns: []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByProvince},
+		ModeTransforms: map[sciensano.SummaryColumn][]config.Transform{
+			sciensano.Total:      {config.Absolute, config.PerHospital, config.Growth, config.DoublingTime},
+			sciensano.ByProvince: {config.Absolute, config.PerHospital, config.Growth, config.DoublingTime, config.Per100k, config.Per100k14d},
+			sciensano.ByRegion:   {config.Absolute, config.PerHospital, config.Growth, config.DoublingTime, config.Per100k, config.Per100k14d},
+			sciensano.ByCategory: {config.Absolute, config.PerHospital},
+		},
+	}, metrics[1])
+	// incidence rates are only generated for the modes for which population figures are available
+	assert.True(t, metrics[0].SupportsTransform(sciensano.ByRegion, config.Per100k))
+	assert.False(t, metrics[0].SupportsTransform(sciensano.Total, config.Per100k))
+	assert.False(t, metrics[0].SupportsTransform(sciensano.ByGender, config.Per100k14d))
+	assert.True(t, metrics[0].SupportsTransform(sciensano.ByGender, ""))
+	assert.Empty(t, metrics[3].Rates)
+	assert.Equal(t, []sciensano.DoseType{sciensano.Partial, sciensano.Full}, metrics[6].DoseTypes)
+	assert.Empty(t, metrics[6].Breakdowns)
+	assert.Equal(t, []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup, sciensano.ByManufacturer, sciensano.ByVaccinationType}, metrics[4].Breakdowns)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr assert.ErrorAssertionFunc
+		want    []string
+	}{
+		{
+			name: "summary",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [ByProvince, ByRegion]
+    transforms: [absolute, per100k]
+`,
+			wantErr: assert.NoError,
+			want:    []string{"cases-ByProvince", "cases-ByProvince-per100k", "cases-ByRegion", "cases-ByRegion-per100k"},
+		},
+		{
+			name: "default transform",
+			input: `
+reports:
+  - name: tests
+    datasource: testResults
+    modes: [ByCategory]
+`,
+			wantErr: assert.NoError,
+			want:    []string{"tests-ByCategory"},
+		},
+		{
+			name: "vaccination rate",
+			input: `
+reports:
+  - name: vaccination-rate
+    type: vaccination-rate
+    modes: [ByRegion]
+    doseTypes: [Partial, Full]
+`,
+			wantErr: assert.NoError,
+			want:    []string{"vaccination-rate-Partial-ByRegion", "vaccination-rate-Full-ByRegion"},
+		},
+		{
+			name: "derived",
+			input: `
+reports:
+  - name: rt
+    type: rt
+    modes: [Total]
+`,
+			wantErr: assert.NoError,
+			want:    []string{"rt-Total"},
+		},
+		{
+			name: "hospitalisation ratio by age group",
+			input: `
+reports:
+  - name: hospitalisation-ratio
+    type: hospitalisation-ratio
+    modes: [ByAgeGroup]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "hospitalisations growth by category",
+			input: `
+reports:
+  - name: hospitalisations
+    datasource: hospitalisations
+    modes: [ByCategory]
+    transforms: [growth]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty",
+			input:   ``,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid yaml",
+			input:   `reports: [`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "unknown field",
+			input: `
+reports:
+  - name: cases
+    source: cases
+    modes: [Total]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing name",
+			input: `
+reports:
+  - datasource: cases
+    modes: [Total]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing modes",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid datasource",
+			input: `
+reports:
+  - name: cases
+    datasource: foo
+    modes: [Total]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid mode for datasource",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [ByManufacturer]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid mode for transform",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total]
+    transforms: [per100k]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid transform",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total]
+    transforms: [per1M]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "per-hospital requires hospitalisations",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total]
+    transforms: [per-hospital]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid type",
+			input: `
+reports:
+  - name: foo
+    type: foo
+    modes: [Total]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "datasource for derived report",
+			input: `
+reports:
+  - name: rt
+    type: rt
+    datasource: cases
+    modes: [Total]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing dose types",
+			input: `
+reports:
+  - name: vaccination-rate
+    type: vaccination-rate
+    modes: [ByRegion]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid dose type",
+			input: `
+reports:
+  - name: vaccination-rate
+    type: vaccination-rate
+    modes: [ByRegion]
+    doseTypes: [Half]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "dose types for summary",
+			input: `
+reports:
+  - name: vaccinations
+    datasource: vaccinations
+    modes: [ByRegion]
+    doseTypes: [Full]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "duplicate report",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total, ByRegion]
+  - name: cases
+    datasource: cases
+    modes: [ByRegion]
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "same name, different datasource",
+			input: `
+reports:
+  - name: cases
+    datasource: cases
+    modes: [Total]
+  - name: cases
+    datasource: mortalities
+    modes: [ByRegion]
+`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Parse(strings.NewReader(tt.input))
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, cfg.Keys())
+		})
+	}
+}
+
+func TestParse_Settings(t *testing.T) {
+	cfg, err := config.Parse(strings.NewReader(`
+pollingInterval: 30m
+demographics: /data/population.txt
+events: /data/events.yaml
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, cfg.PollingInterval)
+	assert.Equal(t, "/data/population.txt", cfg.Demographics)
+	assert.Equal(t, "/data/events.yaml", cfg.Events)
+
+	_, err = config.Parse(strings.NewReader(`pollingInterval: -1m`))
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	_, err := config.Load(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("reports:\n  - name: rt\n    type: rt\n    modes: [Total]\n"), 0644))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rt-Total"}, cfg.Keys())
+
+	require.NoError(t, os.WriteFile(path, []byte("reports:\n  - name: rt\n    type: rt\n    modes: [ByManufacturer]\n"), 0644))
+	_, err = config.Load(path)
+	assert.Error(t, err)
+}
@@ -7,24 +7,38 @@ import (
 )
 
 type populationRecord struct {
-	Region []byte `csv:"TX_RGN_DESCR_NL"`
-	Age    []byte `csv:"CD_AGE"`
-	Count  []byte `csv:"MS_POPULATION\r"`
+	Province []byte `csv:"CD_PROV_REFNIS"`
+	Region   []byte `csv:"TX_RGN_DESCR_NL"`
+	Sex      []byte `csv:"CD_SEX"`
+	Age      []byte `csv:"CD_AGE"`
+	Count    []byte `csv:"MS_POPULATION\r"`
 }
 
-func groupPopulation(filename string) (map[string]int, map[int]int, error) {
+// populationGroups holds the population figures, grouped by region, province (NIS code), age and gender
+type populationGroups struct {
+	byRegion   map[string]int
+	byProvince map[string]int
+	byAge      map[int]int
+	byGender   map[string]int
+}
+
+func groupPopulation(filename string) (populationGroups, error) {
 	var record populationRecord
 	reader, err := csv.NewFileReader(filename, '|', &record)
 	if err != nil {
-		return nil, nil, err
+		return populationGroups{}, err
 	}
 
 	defer func() {
 		_ = reader.Close()
 	}()
 
-	byRegion := make(map[string]int)
-	byAge := make(map[int]int)
+	groups := populationGroups{
+		byRegion:   make(map[string]int),
+		byProvince: make(map[string]int),
+		byAge:      make(map[int]int),
+		byGender:   make(map[string]int),
+	}
 
 	var line int
 	for reader.Scan() {
@@ -35,18 +49,22 @@ func groupPopulation(filename string) (map[string]int, map[int]int, error) {
 		var count int
 		count, err = strconv.Atoi(string(record.Count))
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid number for Count on line %d: %w", line, err)
+			return populationGroups{}, fmt.Errorf("invalid number for Count on line %d: %w", line, err)
 		}
 
 		var age int
 		age, err = strconv.Atoi(string(record.Age))
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid number for Age on line %d: %w", line, err)
+			return populationGroups{}, fmt.Errorf("invalid number for Age on line %d: %w", line, err)
 		}
 
-		byRegion[string(record.Region)] += count
-		byAge[age] += count
+		groups.byRegion[string(record.Region)] += count
+		if len(record.Province) > 0 {
+			groups.byProvince[string(record.Province)] += count
+		}
+		groups.byAge[age] += count
+		groups.byGender[string(record.Sex)] += count
 	}
 
-	return byRegion, byAge, nil
+	return groups, nil
 }
@@ -60,19 +60,24 @@ func unzipTestFiles() (err error) {
 }
 
 func TestStore_groupPopulation(t *testing.T) {
-	byRegion, byAge, err := groupPopulation(path.Join(tmpDir, "demographics.txt"))
+	groups, err := groupPopulation(path.Join(tmpDir, "demographics.txt"))
 	require.NoError(t, err)
-	require.Len(t, byRegion, 3)
-	assert.Contains(t, byRegion, "Waals Gewest")
-	assert.Contains(t, byRegion, "Vlaams Gewest")
-	assert.Contains(t, byRegion, "Brussels Hoofdstedelijk Gewest")
-	assert.NotEmpty(t, byAge)
-	assert.Contains(t, byAge, 52)
+	require.Len(t, groups.byRegion, 3)
+	assert.Contains(t, groups.byRegion, "Waals Gewest")
+	assert.Contains(t, groups.byRegion, "Vlaams Gewest")
+	assert.Contains(t, groups.byRegion, "Brussels Hoofdstedelijk Gewest")
+	assert.NotEmpty(t, groups.byProvince)
+	assert.NotContains(t, groups.byProvince, "")
+	assert.NotEmpty(t, groups.byAge)
+	assert.Contains(t, groups.byAge, 52)
+	require.Len(t, groups.byGender, 2)
+	assert.Contains(t, groups.byGender, "M")
+	assert.Contains(t, groups.byGender, "F")
 }
 
 func BenchmarkStore_groupPopulation(b *testing.B) {
 	for range b.N {
-		_, _, err := groupPopulation(path.Join(tmpDir, "TF_SOC_POP_STRUCT_2021.txt"))
+		_, err := groupPopulation(path.Join(tmpDir, "TF_SOC_POP_STRUCT_2021.txt"))
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -13,13 +13,16 @@ import (
 // Server imports the demographics data on a regular basis and exposes data APIs to callers
 type Server struct {
 	Waiter
-	Path     string
-	Interval time.Duration
-	Logger   *slog.Logger
-	mtime    time.Time
-	byRegion map[string]int
-	byAge    map[int]int
-	lock     sync.RWMutex
+	Path       string
+	Interval   time.Duration
+	Logger     *slog.Logger
+	mtime      time.Time
+	byRegion   map[string]int
+	byProvince map[string]int
+	byAge      map[int]int
+	byGender   map[string]int
+	lock       sync.RWMutex
+	updateLock sync.Mutex
 }
 
 // Run imports the latest demographics data on a regular basis
@@ -44,6 +47,16 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 }
 
+// Reload switches to the demographics file at path and loads it right away, even if it hasn't changed.
+// It returns once the file is loaded, so callers can rely on the new figures. Run doesn't need to be running.
+func (s *Server) Reload(path string) error {
+	s.updateLock.Lock()
+	s.Path = path
+	s.mtime = time.Time{}
+	s.updateLock.Unlock()
+	return s.update()
+}
+
 const ostbelgienPopulation = 78000
 
 // GetForRegion returns the number of people in each region
@@ -75,6 +88,30 @@ func translateRegion(input string) string {
 	return input
 }
 
+// provinceCodes maps the province names used by Sciensano to their NIS code
+var provinceCodes = map[string]string{
+	"Antwerpen":      "10000",
+	"VlaamsBrabant":  "20001",
+	"BrabantWallon":  "20002",
+	"WestVlaanderen": "30000",
+	"OostVlaanderen": "40000",
+	"Hainaut":        "50000",
+	"Liège":          "60000",
+	"Limburg":        "70000",
+	"Luxembourg":     "80000",
+	"Namur":          "90000",
+}
+
+// GetForProvince returns the number of people in a province. Brussels doesn't belong to a province: it returns the population of the Brussels region.
+func (s *Server) GetForProvince(province string) int {
+	if province == "Brussels" {
+		return s.GetForRegion(province)
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.byProvince[provinceCodes[province]]
+}
+
 // GetForAgeBracket returns the number of people within a specific age bracket. Set High to math.Inf(+1)
 // to return all people older than a given age
 func (s *Server) GetForAgeBracket(arguments bracket.Bracket) int {
@@ -93,3 +130,10 @@ func (s *Server) GetForAgeBracket(arguments bracket.Bracket) int {
 	}
 	return total
 }
+
+// GetForGender returns the number of people of the specified gender ("M" or "F")
+func (s *Server) GetForGender(gender string) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.byGender[gender]
+}
@@ -78,6 +78,30 @@ func TestServer_GetByAgeBracket(t *testing.T) {
 	}
 }
 
+func TestServer_GetForGender(t *testing.T) {
+	s := Server{Path: path.Join(tmpDir, "demographics.txt"), Logger: slog.Default()}
+	err := s.update()
+	require.NoError(t, err)
+
+	male, female := s.GetForGender("M"), s.GetForGender("F")
+	assert.NotZero(t, male)
+	assert.NotZero(t, female)
+	assert.Equal(t, s.GetForAgeBracket(bracket.Bracket{}), male+female)
+	assert.Zero(t, s.GetForGender("X"))
+}
+
+func TestServer_GetForProvince(t *testing.T) {
+	s := Server{Path: path.Join(tmpDir, "demographics.txt"), Logger: slog.Default()}
+	err := s.update()
+	require.NoError(t, err)
+
+	assert.NotZero(t, s.GetForProvince("VlaamsBrabant"))
+	assert.NotZero(t, s.GetForProvince("Luxembourg"))
+	assert.NotZero(t, s.GetForProvince("Brussels"))
+	assert.Equal(t, s.GetForRegion("Brussels"), s.GetForProvince("Brussels"))
+	assert.Zero(t, s.GetForProvince("Atlantis"))
+}
+
 func TestStore_Run(t *testing.T) {
 	s := Server{
 		Path:     path.Join(tmpDir, "demographics.txt"),
@@ -101,3 +125,31 @@ func TestStore_Run(t *testing.T) {
 	cancel()
 	assert.NoError(t, <-ch)
 }
+
+func TestStore_Reload(t *testing.T) {
+	s := Server{
+		Path:     path.Join(tmpDir, "demographics.txt"),
+		Interval: time.Hour,
+		Logger:   slog.Default(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan error)
+	go func() {
+		ch <- s.Run(ctx)
+	}()
+
+	ctx2, cancel2 := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel2()
+	require.NoError(t, s.WaitTillReady(ctx2))
+	before := s.GetForRegion("Flanders")
+
+	require.NoError(t, s.Reload(path.Join(tmpDir, "TF_SOC_POP_STRUCT_2021.txt")))
+	assert.NotEqual(t, before, s.GetForRegion("Flanders"))
+
+	assert.Error(t, s.Reload(path.Join(tmpDir, "missing.txt")))
+
+	cancel()
+	assert.NoError(t, <-ch)
+}
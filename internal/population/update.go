@@ -6,6 +6,8 @@ import (
 )
 
 func (s *Server) update() error {
+	s.updateLock.Lock()
+	defer s.updateLock.Unlock()
 	mtime, updated, err := s.isUpdated()
 	if err != nil || !updated {
 		return err
@@ -30,12 +32,14 @@ func (s *Server) isUpdated() (time.Time, bool, error) {
 func (s *Server) process() error {
 	s.Logger.Info("loading demographics")
 	start := time.Now()
-	byRegion, byAge, err := groupPopulation(s.Path)
+	groups, err := groupPopulation(s.Path)
 	if err == nil {
 		s.lock.Lock()
 		defer s.lock.Unlock()
-		s.byRegion = byRegion
-		s.byAge = byAge
+		s.byRegion = groups.byRegion
+		s.byProvince = groups.byProvince
+		s.byAge = groups.byAge
+		s.byGender = groups.byGender
 
 		s.Logger.Info("loaded demographics", "duration", time.Since(start))
 	}
@@ -22,7 +22,7 @@ func (w *Waiter) Ready() {
 }
 
 func (w *Waiter) WaitTillReady(ctx context.Context) error {
-	if w.isReady() {
+	if w.IsReady() {
 		return nil
 	}
 
@@ -35,7 +35,8 @@ func (w *Waiter) WaitTillReady(ctx context.Context) error {
 	}
 }
 
-func (w *Waiter) isReady() bool {
+// IsReady reports whether the population data has been loaded
+func (w *Waiter) IsReady() bool {
 	w.lock.Lock()
 	defer w.lock.Unlock()
 	return w.ready
@@ -0,0 +1,63 @@
+package datasource
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores the latest data of a DataSource, so it can be published right away after a restart.
+type Cache[T any] interface {
+	Load() (T, time.Time, error)
+	Save(data T, lastModified time.Time) error
+}
+
+var _ Cache[int] = &FileCache[int]{}
+
+// FileCache stores the data of a DataSource, and its modification time, in a file.
+type FileCache[T any] struct {
+	Path string
+}
+
+type cacheEntry[T any] struct {
+	LastModified time.Time
+	Data         T
+}
+
+// Load returns the cached data and its modification time. If there is no cached data, the returned error wraps fs.ErrNotExist.
+func (c *FileCache[T]) Load() (T, time.Time, error) {
+	var entry cacheEntry[T]
+	f, err := os.Open(c.Path)
+	if err != nil {
+		return entry.Data, time.Time{}, fmt.Errorf("cache: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err = gob.NewDecoder(f).Decode(&entry); err != nil {
+		return entry.Data, time.Time{}, fmt.Errorf("cache: %s: %w", c.Path, err)
+	}
+	return entry.Data, entry.LastModified, nil
+}
+
+// Save writes the data and its modification time to the cache. The data is written to a temporary file first,
+// so a failed write doesn't corrupt the previous version.
+func (c *FileCache[T]) Save(data T, lastModified time.Time) error {
+	f, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	err = gob.NewEncoder(f).Encode(cacheEntry[T]{LastModified: lastModified, Data: data})
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("cache: %s: %w", c.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package datasource_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	c := datasource.FileCache[sciensano.Vaccinations]{Path: filepath.Join(t.TempDir(), "vaccinations.gob")}
+
+	_, _, err := c.Load()
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	data := sciensano.Vaccinations{
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}, Region: "Flanders", AgeGroup: "85+", Gender: "F", Dose: sciensano.Full, Count: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)}, Region: "Brussels", AgeGroup: "45-54", Gender: "M", Dose: sciensano.Booster, Count: 5},
+	}
+	lastModified := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Save(data, lastModified))
+
+	loaded, timestamp, err := c.Load()
+	require.NoError(t, err)
+	assert.Equal(t, lastModified, timestamp.UTC())
+	require.Len(t, loaded, len(data))
+	for i := range data {
+		assert.True(t, data[i].TimeStamp.Equal(loaded[i].TimeStamp.Time))
+		loaded[i].TimeStamp = data[i].TimeStamp
+	}
+	assert.Equal(t, data, loaded)
+
+	require.NoError(t, os.WriteFile(c.Path, []byte("not a cache"), 0644))
+	_, _, err = c.Load()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+}
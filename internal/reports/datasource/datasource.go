@@ -2,7 +2,9 @@ package datasource
 
 import (
 	"context"
+	"errors"
 	"github.com/clambin/go-common/taskmanager"
+	"io/fs"
 	"log/slog"
 	"math/rand"
 	"sync"
@@ -10,19 +12,33 @@ import (
 )
 
 type Fetcher[T any] interface {
-	GetLastModified(ctx context.Context) (time.Time, error)
-	Fetch(ctx context.Context) (T, error)
+	// Fetch returns the data, its modification time and whether it changed since the previous call.
+	Fetch(ctx context.Context) (T, time.Time, bool, error)
+}
+
+// lastModifiedSetter is implemented by Fetchers that can send a conditional request for data that was already retrieved.
+type lastModifiedSetter interface {
+	SetLastModified(lastModified time.Time)
 }
 
 var _ taskmanager.Task = &DataSource[int]{}
 
 type DataSource[T any] struct {
 	Publisher[T]
+	Name            string
 	Fetcher         Fetcher[T]
+	Cache           Cache[T]
+	Retry           RetryPolicy
+	Metrics         *Metrics
+	Updates         *UpdateLog
 	PollingInterval time.Duration
 	Logger          *slog.Logger
 	currentData     T
 	currentAge      time.Time
+	lastPoll        time.Time
+	lastRecord      time.Time
+	records         int
+	failing         bool
 	lock            sync.RWMutex
 }
 
@@ -33,6 +49,10 @@ func (d *DataSource[T]) GetCurrentAge() time.Time {
 }
 
 func (d *DataSource[T]) Run(ctx context.Context) error {
+	if d.Cache != nil {
+		d.loadCache()
+	}
+
 	if err := d.fetchData(ctx); err != nil {
 		d.Logger.Error("failed to collect data", "err", err)
 	} else {
@@ -41,11 +61,15 @@ func (d *DataSource[T]) Run(ctx context.Context) error {
 
 	ticker := time.NewTicker(jitter(d.PollingInterval, 0.04, rand.Float64()))
 	defer ticker.Stop()
+	registered := d.Registered()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-registered:
+			// new subscribers (e.g. the reporters of a reloaded configuration) get the current data right away
+			d.sendData()
 		case <-ticker.C:
 			if err := d.fetchData(ctx); err != nil {
 				d.Logger.Error("failed to collect data", "err", err)
@@ -64,19 +88,74 @@ func jitter(interval time.Duration, width float64, randFactor float64) time.Dura
 }
 
 func (d *DataSource[T]) fetchData(ctx context.Context) error {
+	data, timestamp, changed, err := d.fetchWithRetry(ctx)
+	if err != nil || !changed {
+		return err
+	}
 	d.lock.Lock()
 	defer d.lock.Unlock()
-	timestamp, err := d.Fetcher.GetLastModified(ctx)
-	if !timestamp.After(d.currentAge) || err != nil {
-		return err
+	if !timestamp.After(d.currentAge) {
+		return nil
+	}
+	d.setCurrentData(data, timestamp)
+	d.Logger.Info("new data found")
+	if d.Cache != nil {
+		if err = d.Cache.Save(data, timestamp); err != nil {
+			d.Logger.Warn("failed to save data to cache", "err", err)
+		}
 	}
-	data, err := d.Fetcher.Fetch(ctx)
-	if err == nil {
-		d.currentData = data
-		d.currentAge = timestamp
-		d.Logger.Info("new data found")
+	return nil
+}
+
+// fetchWithRetry calls the Fetcher, retrying failed attempts as determined by the DataSource's RetryPolicy.
+func (d *DataSource[T]) fetchWithRetry(ctx context.Context) (T, time.Time, bool, error) {
+	for attempt := 1; ; attempt++ {
+		data, timestamp, changed, err := d.Fetcher.Fetch(ctx)
+		if err == nil {
+			d.setLastPoll(time.Now())
+			if d.failing {
+				d.failing = false
+				d.Metrics.recovery(d.Name)
+				d.Logger.Info("datasource recovered", "attempts", attempt)
+			}
+			return data, timestamp, changed, nil
+		}
+		if errors.Is(err, context.Canceled) {
+			return data, timestamp, false, err
+		}
+		d.failing = true
+		d.Metrics.failure(d.Name)
+		if !d.Retry.shouldRetry(attempt, err) {
+			return data, timestamp, false, err
+		}
+		wait := d.Retry.backoff(attempt)
+		d.Logger.Warn("failed to collect data. retrying", "err", err, "attempt", attempt, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return data, timestamp, false, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// loadCache publishes the cached data, so subscribers don't need to wait for the first fetch to complete.
+// If the Fetcher supports it, the first fetch only downloads the data if it changed since it was cached.
+func (d *DataSource[T]) loadCache() {
+	data, timestamp, err := d.Cache.Load()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			d.Logger.Warn("failed to load data from cache", "err", err)
+		}
+		return
+	}
+	d.lock.Lock()
+	d.setCurrentData(data, timestamp)
+	d.lock.Unlock()
+	if f, ok := d.Fetcher.(lastModifiedSetter); ok {
+		f.SetLastModified(timestamp)
 	}
-	return err
+	d.Logger.Info("cached data loaded", "lastModified", timestamp)
+	d.sendData()
 }
 
 func (d *DataSource[T]) sendData() {
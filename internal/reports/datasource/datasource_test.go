@@ -2,10 +2,16 @@ package datasource_test
 
 import (
 	"context"
+	"errors"
 	"github.com/clambin/sciensano/v2/internal/reports/datasource"
 	"github.com/clambin/sciensano/v2/internal/reports/datasource/mocks"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"log/slog"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -14,8 +20,7 @@ func TestDataSource(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	f := mocks.NewFetcher[int](t)
-	f.EXPECT().GetLastModified(ctx).Return(time.Now(), nil)
-	f.EXPECT().Fetch(ctx).Return(100, nil)
+	f.EXPECT().Fetch(ctx).Return(100, time.Now(), true, nil)
 
 	ds := datasource.DataSource[int]{
 		Fetcher:         f,
@@ -43,12 +48,11 @@ func TestDataSource_LateSubscriber(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	f := mocks.NewFetcher[int](t)
-	f.EXPECT().GetLastModified(ctx).Return(time.Date(2023, time.August, 15, 0, 0, 0, 0, time.UTC), nil)
-	f.EXPECT().Fetch(ctx).Return(100, nil)
+	f.EXPECT().Fetch(ctx).Return(100, time.Date(2023, time.August, 15, 0, 0, 0, 0, time.UTC), true, nil)
 
 	ds := datasource.DataSource[int]{
 		Fetcher:         f,
-		PollingInterval: 100 * time.Millisecond,
+		PollingInterval: time.Hour,
 		Logger:          slog.Default().With("datasource", "test"),
 	}
 
@@ -64,7 +68,7 @@ func TestDataSource_LateSubscriber(t *testing.T) {
 	assert.Equal(t, 100, <-dataCh)
 	ds.Unregister(dataCh)
 
-	// new subscribers also get the data, even if they subscribe after the data was collected
+	// new subscribers also get the data, even if they subscribe after the data was collected, without waiting for the next poll
 	dataCh = make(chan int)
 	ds.Register(dataCh)
 	assert.Equal(t, 100, <-dataCh)
@@ -72,3 +76,239 @@ func TestDataSource_LateSubscriber(t *testing.T) {
 	cancel()
 	assert.ErrorIs(t, <-errCh, context.Canceled)
 }
+
+func TestDataSource_Cache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := datasource.FileCache[int]{Path: filepath.Join(t.TempDir(), "test.gob")}
+	cachedAge := time.Date(2023, time.August, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Save(100, cachedAge))
+
+	// upstream only has older data: the cached data is kept
+	f := mocks.NewFetcher[int](t)
+	f.EXPECT().Fetch(ctx).Return(50, cachedAge.Add(-time.Hour), true, nil).Once()
+	// newer data gets published and replaces the cached data
+	f.EXPECT().Fetch(ctx).Return(200, cachedAge.Add(time.Hour), true, nil)
+
+	ds := datasource.DataSource[int]{
+		Fetcher:         f,
+		Cache:           &c,
+		PollingInterval: 100 * time.Millisecond,
+		Logger:          slog.Default().With("datasource", "test"),
+	}
+
+	dataCh := make(chan int)
+	ds.Register(dataCh)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ds.Run(ctx)
+	}()
+
+	assert.Equal(t, 100, <-dataCh)
+	assert.Equal(t, 200, <-dataCh)
+	assert.Equal(t, cachedAge.Add(time.Hour), ds.GetCurrentAge())
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+
+	data, age, err := c.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 200, data)
+	assert.Equal(t, cachedAge.Add(time.Hour), age.UTC())
+}
+
+// conditionalFetcher is a Fetcher that records the modification time set by the DataSource
+type conditionalFetcher struct {
+	*mocks.Fetcher[int]
+	lastModified time.Time
+}
+
+func (f *conditionalFetcher) SetLastModified(lastModified time.Time) {
+	f.lastModified = lastModified
+}
+
+func TestDataSource_Cache_LastModified(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := datasource.FileCache[int]{Path: filepath.Join(t.TempDir(), "test.gob")}
+	cachedAge := time.Date(2023, time.August, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Save(100, cachedAge))
+
+	// the cached data didn't change upstream
+	f := conditionalFetcher{Fetcher: mocks.NewFetcher[int](t)}
+	f.EXPECT().Fetch(ctx).Return(0, time.Time{}, false, nil).Once()
+
+	ds := datasource.DataSource[int]{
+		Fetcher:         &f,
+		Cache:           &c,
+		PollingInterval: time.Hour,
+		Logger:          slog.Default().With("datasource", "test"),
+	}
+
+	dataCh := make(chan int)
+	ds.Register(dataCh)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ds.Run(ctx)
+	}()
+
+	assert.Equal(t, 100, <-dataCh)
+	assert.Eventually(t, func() bool { return !ds.GetStatus().LastPoll.IsZero() }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, cachedAge, f.lastModified.UTC())
+	assert.Equal(t, cachedAge, ds.GetCurrentAge().UTC())
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestDataSource_Retry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := mocks.NewFetcher[int](t)
+	f.EXPECT().Fetch(ctx).Return(0, time.Time{}, false, errors.New("transient")).Twice()
+	f.EXPECT().Fetch(ctx).Return(100, time.Now(), true, nil)
+
+	m := datasource.NewMetrics("", "")
+	ds := datasource.DataSource[int]{
+		Name:            "test",
+		Fetcher:         f,
+		Retry:           datasource.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		Metrics:         m,
+		PollingInterval: time.Hour,
+		Logger:          slog.Default().With("datasource", "test"),
+	}
+
+	dataCh := make(chan int)
+	ds.Register(dataCh)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ds.Run(ctx)
+	}()
+
+	assert.Equal(t, 100, <-dataCh)
+
+	assert.NoError(t, testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP datasource_failing Set to 1 if the last fetch attempt of the datasource failed
+# TYPE datasource_failing gauge
+datasource_failing{datasource="test"} 0
+# HELP datasource_fetch_failures_total Number of failed fetch attempts per datasource
+# TYPE datasource_fetch_failures_total counter
+datasource_fetch_failures_total{datasource="test"} 2
+# HELP datasource_fetch_recoveries_total Number of successful fetches after one or more failed attempts per datasource
+# TYPE datasource_fetch_recoveries_total counter
+datasource_fetch_recoveries_total{datasource="test"} 1
+`), "datasource_failing", "datasource_fetch_failures_total", "datasource_fetch_recoveries_total"))
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestDataSource_Retry_NotRetryable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := mocks.NewFetcher[int](t)
+	f.EXPECT().Fetch(ctx).Return(0, time.Time{}, false, errors.New("permanent")).Once()
+
+	m := datasource.NewMetrics("", "")
+	ds := datasource.DataSource[int]{
+		Name:            "test",
+		Fetcher:         f,
+		Retry:           datasource.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: func(error) bool { return false }},
+		Metrics:         m,
+		PollingInterval: time.Hour,
+		Logger:          slog.Default().With("datasource", "test"),
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- ds.Run(ctx)
+	}()
+
+	// non-retryable errors are only attempted once
+	assert.Eventually(t, func() bool {
+		return testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP datasource_fetch_failures_total Number of failed fetch attempts per datasource
+# TYPE datasource_fetch_failures_total counter
+datasource_fetch_failures_total{datasource="test"} 1
+`), "datasource_fetch_failures_total") == nil
+	}, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestDataSource_GetStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lastModified := time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC)
+	f := mocks.NewFetcher[sciensano.Mortalities](t)
+	f.EXPECT().Fetch(ctx).Return(sciensano.Mortalities{
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}},
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)}},
+	}, lastModified, true, nil)
+
+	m := datasource.NewMetrics("", "")
+	var updates datasource.UpdateLog
+	ds := datasource.DataSource[sciensano.Mortalities]{
+		Name:            "mortalities",
+		Fetcher:         f,
+		Metrics:         m,
+		Updates:         &updates,
+		PollingInterval: time.Hour,
+		Logger:          slog.Default().With("datasource", "test"),
+	}
+
+	dataCh := make(chan sciensano.Mortalities)
+	ds.Register(dataCh)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ds.Run(ctx)
+	}()
+	<-dataCh
+
+	status := ds.GetStatus()
+	assert.Equal(t, "mortalities", status.Name)
+	assert.Equal(t, lastModified, status.LastModified)
+	assert.Equal(t, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), status.LastRecord)
+	assert.Equal(t, 2, status.Records)
+	assert.WithinDuration(t, time.Now(), status.LastPoll, time.Minute)
+
+	assert.NoError(t, testutil.CollectAndCompare(m, strings.NewReader(`
+# HELP datasource_last_modified_timestamp_seconds Modification time of the datasource's data, as reported by the upstream source
+# TYPE datasource_last_modified_timestamp_seconds gauge
+datasource_last_modified_timestamp_seconds{datasource="mortalities"} 1.7094672e+09
+# HELP datasource_last_record_timestamp_seconds Date of the most recent record in the datasource's data
+# TYPE datasource_last_record_timestamp_seconds gauge
+datasource_last_record_timestamp_seconds{datasource="mortalities"} 1.7093376e+09
+# HELP datasource_records Number of records in the datasource's data
+# TYPE datasource_records gauge
+datasource_records{datasource="mortalities"} 2
+`), "datasource_last_modified_timestamp_seconds", "datasource_last_record_timestamp_seconds", "datasource_records"))
+	assert.Equal(t, 1, testutil.CollectAndCount(m, "datasource_last_poll_timestamp_seconds"))
+	assert.Equal(t, []datasource.Update{{Name: "mortalities", LastModified: lastModified, Records: 2}}, updates.GetUpdates(lastModified, lastModified))
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestStatus_Stale(t *testing.T) {
+	now := time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		status    datasource.Status
+		threshold time.Duration
+		want      bool
+	}{
+		{name: "fresh", status: datasource.Status{LastPoll: now.Add(-time.Minute), LastModified: now.Add(-time.Hour)}, threshold: 24 * time.Hour, want: false},
+		{name: "polling failed", status: datasource.Status{LastPoll: now.Add(-25 * time.Hour), LastModified: now.Add(-time.Hour)}, threshold: 24 * time.Hour, want: true},
+		{name: "upstream not updated", status: datasource.Status{LastPoll: now.Add(-time.Minute), LastModified: now.Add(-48 * time.Hour)}, threshold: 24 * time.Hour, want: true},
+		{name: "not polled yet", status: datasource.Status{}, threshold: 24 * time.Hour, want: false},
+		{name: "not polled yet, cached data", status: datasource.Status{LastModified: now.Add(-48 * time.Hour)}, threshold: 24 * time.Hour, want: false},
+		{name: "disabled", status: datasource.Status{}, want: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.Stale(tt.threshold, now))
+		})
+	}
+}
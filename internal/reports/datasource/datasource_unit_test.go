@@ -1,6 +1,8 @@
 package datasource
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -15,3 +17,24 @@ func Test_jitter(t *testing.T) {
 	//assert.Equal(t, 500*time.Millisecond, jitter(500*time.Millisecond, 0.1, 0.5))
 	assert.Equal(t, 510*time.Millisecond, jitter(500*time.Millisecond, 0.04, 1))
 }
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		got := p.backoff(attempt + 1)
+		assert.GreaterOrEqual(t, got, want*3/4)
+		assert.LessOrEqual(t, got, want*5/4)
+	}
+}
+
+func TestRetryPolicy_shouldRetry(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 2}
+	assert.True(t, p.shouldRetry(1, errors.New("fail")))
+	assert.False(t, p.shouldRetry(2, errors.New("fail")))
+	assert.False(t, p.shouldRetry(1, context.Canceled))
+	assert.False(t, RetryPolicy{}.shouldRetry(1, errors.New("fail")))
+
+	p.Retryable = func(err error) bool { return err.Error() == "transient" }
+	assert.True(t, p.shouldRetry(1, errors.New("transient")))
+	assert.False(t, p.shouldRetry(1, errors.New("fail")))
+}
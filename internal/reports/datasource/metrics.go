@@ -0,0 +1,119 @@
+package datasource
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+var _ prometheus.Collector = &Metrics{}
+
+// Metrics records the fetch failures and recoveries, and the freshness of the data, of each DataSource.
+type Metrics struct {
+	failures   *prometheus.CounterVec
+	recoveries *prometheus.CounterVec
+	failing    *prometheus.GaugeVec
+	lastPoll   *prometheus.GaugeVec
+	modified   *prometheus.GaugeVec
+	records    *prometheus.GaugeVec
+	lastRecord *prometheus.GaugeVec
+}
+
+// NewMetrics creates a new Metrics collector.
+func NewMetrics(namespace, subsystem string) *Metrics {
+	return &Metrics{
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_fetch_failures_total",
+			Help:      "Number of failed fetch attempts per datasource",
+		}, []string{"datasource"}),
+		recoveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_fetch_recoveries_total",
+			Help:      "Number of successful fetches after one or more failed attempts per datasource",
+		}, []string{"datasource"}),
+		failing: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_failing",
+			Help:      "Set to 1 if the last fetch attempt of the datasource failed",
+		}, []string{"datasource"}),
+		lastPoll: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_last_poll_timestamp_seconds",
+			Help:      "Time of the last successful poll of the datasource",
+		}, []string{"datasource"}),
+		modified: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_last_modified_timestamp_seconds",
+			Help:      "Modification time of the datasource's data, as reported by the upstream source",
+		}, []string{"datasource"}),
+		records: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_records",
+			Help:      "Number of records in the datasource's data",
+		}, []string{"datasource"}),
+		lastRecord: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "datasource_last_record_timestamp_seconds",
+			Help:      "Date of the most recent record in the datasource's data",
+		}, []string{"datasource"}),
+	}
+}
+
+func (m *Metrics) failure(name string) {
+	if m == nil {
+		return
+	}
+	m.failures.WithLabelValues(name).Inc()
+	m.failing.WithLabelValues(name).Set(1)
+}
+
+func (m *Metrics) recovery(name string) {
+	if m == nil {
+		return
+	}
+	m.recoveries.WithLabelValues(name).Inc()
+	m.failing.WithLabelValues(name).Set(0)
+}
+
+func (m *Metrics) poll(name string, timestamp time.Time) {
+	if m == nil {
+		return
+	}
+	m.lastPoll.WithLabelValues(name).Set(float64(timestamp.Unix()))
+}
+
+func (m *Metrics) data(name string, lastModified time.Time, records int, lastRecord time.Time) {
+	if m == nil {
+		return
+	}
+	m.modified.WithLabelValues(name).Set(float64(lastModified.Unix()))
+	m.records.WithLabelValues(name).Set(float64(records))
+	m.lastRecord.WithLabelValues(name).Set(float64(lastRecord.Unix()))
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.failures.Describe(ch)
+	m.recoveries.Describe(ch)
+	m.failing.Describe(ch)
+	m.lastPoll.Describe(ch)
+	m.modified.Describe(ch)
+	m.records.Describe(ch)
+	m.lastRecord.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.failures.Collect(ch)
+	m.recoveries.Collect(ch)
+	m.failing.Collect(ch)
+	m.lastPoll.Collect(ch)
+	m.modified.Collect(ch)
+	m.records.Collect(ch)
+	m.lastRecord.Collect(ch)
+}
@@ -24,12 +24,14 @@ func (_m *Fetcher[T]) EXPECT() *Fetcher_Expecter[T] {
 }
 
 // Fetch provides a mock function with given fields: ctx
-func (_m *Fetcher[T]) Fetch(ctx context.Context) (T, error) {
+func (_m *Fetcher[T]) Fetch(ctx context.Context) (T, time.Time, bool, error) {
 	ret := _m.Called(ctx)
 
 	var r0 T
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (T, error)); ok {
+	var r1 time.Time
+	var r2 bool
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context) (T, time.Time, bool, error)); ok {
 		return rf(ctx)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context) T); ok {
@@ -38,91 +40,51 @@ func (_m *Fetcher[T]) Fetch(ctx context.Context) (T, error) {
 		r0 = ret.Get(0).(T)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context) time.Time); ok {
 		r1 = rf(ctx)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(time.Time)
 	}
 
-	return r0, r1
-}
-
-// Fetcher_Fetch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fetch'
-type Fetcher_Fetch_Call[T interface{}] struct {
-	*mock.Call
-}
-
-// Fetch is a helper method to define mock.On call
-//   - ctx context.Context
-func (_e *Fetcher_Expecter[T]) Fetch(ctx interface{}) *Fetcher_Fetch_Call[T] {
-	return &Fetcher_Fetch_Call[T]{Call: _e.mock.On("Fetch", ctx)}
-}
-
-func (_c *Fetcher_Fetch_Call[T]) Run(run func(ctx context.Context)) *Fetcher_Fetch_Call[T] {
-	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
-	})
-	return _c
-}
-
-func (_c *Fetcher_Fetch_Call[T]) Return(_a0 T, _a1 error) *Fetcher_Fetch_Call[T] {
-	_c.Call.Return(_a0, _a1)
-	return _c
-}
-
-func (_c *Fetcher_Fetch_Call[T]) RunAndReturn(run func(context.Context) (T, error)) *Fetcher_Fetch_Call[T] {
-	_c.Call.Return(run)
-	return _c
-}
-
-// GetLastModified provides a mock function with given fields: ctx
-func (_m *Fetcher[T]) GetLastModified(ctx context.Context) (time.Time, error) {
-	ret := _m.Called(ctx)
-
-	var r0 time.Time
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (time.Time, error)); ok {
-		return rf(ctx)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context) time.Time); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(2).(func(context.Context) bool); ok {
+		r2 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(time.Time)
+		r2 = ret.Get(2).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(3).(func(context.Context) error); ok {
+		r3 = rf(ctx)
 	} else {
-		r1 = ret.Error(1)
+		r3 = ret.Error(3)
 	}
 
-	return r0, r1
+	return r0, r1, r2, r3
 }
 
-// Fetcher_GetLastModified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastModified'
-type Fetcher_GetLastModified_Call[T interface{}] struct {
+// Fetcher_Fetch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fetch'
+type Fetcher_Fetch_Call[T interface{}] struct {
 	*mock.Call
 }
 
-// GetLastModified is a helper method to define mock.On call
+// Fetch is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *Fetcher_Expecter[T]) GetLastModified(ctx interface{}) *Fetcher_GetLastModified_Call[T] {
-	return &Fetcher_GetLastModified_Call[T]{Call: _e.mock.On("GetLastModified", ctx)}
+func (_e *Fetcher_Expecter[T]) Fetch(ctx interface{}) *Fetcher_Fetch_Call[T] {
+	return &Fetcher_Fetch_Call[T]{Call: _e.mock.On("Fetch", ctx)}
 }
 
-func (_c *Fetcher_GetLastModified_Call[T]) Run(run func(ctx context.Context)) *Fetcher_GetLastModified_Call[T] {
+func (_c *Fetcher_Fetch_Call[T]) Run(run func(ctx context.Context)) *Fetcher_Fetch_Call[T] {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *Fetcher_GetLastModified_Call[T]) Return(_a0 time.Time, _a1 error) *Fetcher_GetLastModified_Call[T] {
-	_c.Call.Return(_a0, _a1)
+func (_c *Fetcher_Fetch_Call[T]) Return(_a0 T, _a1 time.Time, _a2 bool, _a3 error) *Fetcher_Fetch_Call[T] {
+	_c.Call.Return(_a0, _a1, _a2, _a3)
 	return _c
 }
 
-func (_c *Fetcher_GetLastModified_Call[T]) RunAndReturn(run func(context.Context) (time.Time, error)) *Fetcher_GetLastModified_Call[T] {
+func (_c *Fetcher_Fetch_Call[T]) RunAndReturn(run func(context.Context) (T, time.Time, bool, error)) *Fetcher_Fetch_Call[T] {
 	_c.Call.Return(run)
 	return _c
 }
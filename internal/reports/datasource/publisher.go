@@ -6,8 +6,9 @@ import (
 )
 
 type Publisher[T any] struct {
-	lock    sync.RWMutex
-	clients map[chan T]time.Time
+	lock       sync.RWMutex
+	clients    map[chan T]time.Time
+	registered chan struct{}
 }
 
 func (p *Publisher[T]) Register(ch chan T) {
@@ -17,24 +18,78 @@ func (p *Publisher[T]) Register(ch chan T) {
 		p.clients = make(map[chan T]time.Time)
 	}
 	p.clients[ch] = time.Time{}
+	select {
+	case p.registeredChannel() <- struct{}{}:
+	default:
+	}
 }
 
-func (p *Publisher[T]) Unregister(ch chan T) {
+// Registered returns a channel that receives a value when a client registers, so the owner of the Publisher can publish
+// its current data to the new client right away, rather than waiting for new data.
+func (p *Publisher[T]) Registered() <-chan struct{} {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	return p.registeredChannel()
+}
+
+func (p *Publisher[T]) registeredChannel() chan struct{} {
+	if p.registered == nil {
+		p.registered = make(chan struct{}, 1)
+	}
+	return p.registered
+}
+
+// Unregister removes the client. Publish holds the lock while it sends to a client. If the client already stopped
+// receiving, Publish would block forever and Unregister could never take the lock. So, until the client is removed,
+// Unregister receives (and discards) any data sent to it.
+func (p *Publisher[T]) Unregister(ch chan T) {
+	done := make(chan struct{})
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case <-ch:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	p.lock.Lock()
 	delete(p.clients, ch)
+	p.lock.Unlock()
+
+	close(done)
+	<-drained
 }
 
 func (p *Publisher[T]) Publish(value T, currentAge time.Time) bool {
+	sent := p.send(value, currentAge)
+	if len(sent) == 0 {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, ch := range sent {
+		if _, ok := p.clients[ch]; ok {
+			p.clients[ch] = currentAge
+		}
+	}
+	return true
+}
+
+// send sends the value to all clients that haven't received it yet and returns those clients.
+func (p *Publisher[T]) send(value T, currentAge time.Time) []chan T {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
 
-	var sent bool
+	var sent []chan T
 	for ch, lastSent := range p.clients {
 		if lastSent.Before(currentAge) {
 			ch <- value
-			p.clients[ch] = currentAge
-			sent = true
+			sent = append(sent, ch)
 		}
 	}
 	return sent
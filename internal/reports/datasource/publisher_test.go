@@ -0,0 +1,56 @@
+package datasource_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPublisher(t *testing.T) {
+	var p datasource.Publisher[int]
+	ch := make(chan int)
+	p.Register(ch)
+
+	now := time.Now()
+	sent := make(chan bool)
+	go func() { sent <- p.Publish(1, now) }()
+	assert.Equal(t, 1, <-ch)
+	assert.True(t, <-sent)
+
+	// data is only sent once to each client
+	assert.False(t, p.Publish(1, now))
+
+	p.Unregister(ch)
+	assert.False(t, p.Publish(2, now.Add(time.Hour)))
+}
+
+func TestPublisher_Unregister(t *testing.T) {
+	var p datasource.Publisher[int]
+	ch := make(chan int)
+	p.Register(ch)
+
+	// Publish blocks, as the client isn't receiving
+	published := make(chan struct{})
+	go func() {
+		p.Publish(1, time.Now())
+		close(published)
+	}()
+	// give Publish time to block on the send
+	time.Sleep(100 * time.Millisecond)
+
+	// a client that stops receiving can still unregister, and a blocked Publish completes
+	unregistered := make(chan struct{})
+	go func() {
+		p.Unregister(ch)
+		close(unregistered)
+	}()
+
+	for _, ch := range []chan struct{}{unregistered, published} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy determines how a DataSource retries a failed fetch. The zero value doesn't retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a fetch is attempted during one polling interval.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry. The backoff doubles with each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time between retries. Zero means no limit.
+	MaxBackoff time.Duration
+	// Retryable reports whether a failed fetch should be retried. If nil, all errors are retried, except for a cancelled context.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy retries a failed fetch four times, waiting 10 seconds before the first retry, up to 2 minutes.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 10 * time.Second,
+	MaxBackoff:     2 * time.Minute,
+}
+
+func (r RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if attempt >= r.MaxAttempts {
+		return false
+	}
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// backoff returns the time to wait after the given (1-based) failed attempt, with 50% jitter.
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	delay := r.InitialBackoff
+	for i := 1; i < attempt && (r.MaxBackoff == 0 || delay < r.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+	return jitter(delay, 0.5, rand.Float64())
+}
@@ -1,51 +1,97 @@
 package datasource
 
 import (
+	"fmt"
 	"github.com/clambin/go-common/taskmanager"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 )
 
 type SciensanoSources struct {
 	taskmanager.Manager
-	Cases            DataSource[sciensano.Cases]
-	Hospitalisations DataSource[sciensano.Hospitalisations]
-	Mortalities      DataSource[sciensano.Mortalities]
-	TestResults      DataSource[sciensano.TestResults]
-	Vaccinations     DataSource[sciensano.Vaccinations]
+	Cases             DataSource[sciensano.Cases]
+	Hospitalisations  DataSource[sciensano.Hospitalisations]
+	Mortalities       DataSource[sciensano.Mortalities]
+	TestResults       DataSource[sciensano.TestResults]
+	Vaccinations      DataSource[sciensano.Vaccinations]
+	MunicipalityCases DataSource[sciensano.MunicipalityCases]
 }
 
-func NewSciensanoDatastore(url string, pollingInterval time.Duration, httpClient *http.Client, logger *slog.Logger) *SciensanoSources {
+// NewSciensanoDatastore creates the datasources for all Sciensano feeds. formats selects the format in which each endpoint is retrieved.
+// Endpoints that aren't listed are retrieved as JSON.
+func NewSciensanoDatastore(url string, formats map[sciensano.Endpoint]sciensano.Format, pollingInterval time.Duration, httpClient *http.Client, logger *slog.Logger) *SciensanoSources {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
+	return newSciensanoSources(source{url: url, client: httpClient, formats: formats}, pollingInterval, logger)
+}
+
+// NewLocalSciensanoDatastore creates the datasources for all Sciensano feeds, reading the feeds from local files instead of
+// the Sciensano API. path is either a directory holding the files, or a tarball (optionally gzip-compressed) of snapshot files.
+// Files use the same names as the Sciensano API, e.g. COVID19BE_VACC.json.
+func NewLocalSciensanoDatastore(path string, formats map[sciensano.Endpoint]sciensano.Format, pollingInterval time.Duration, logger *slog.Logger) (*SciensanoSources, error) {
+	stats, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("local datasource: %w", err)
+	}
+	src := source{formats: formats}
+	if stats.IsDir() {
+		src.dir = path
+	} else {
+		src.archive = path
+	}
+	return newSciensanoSources(src, pollingInterval, logger), nil
+}
+
+func newSciensanoSources(src source, pollingInterval time.Duration, logger *slog.Logger) *SciensanoSources {
+	retryPolicy := DefaultRetryPolicy
+	retryPolicy.Retryable = sciensano.IsRetryable
 	store := SciensanoSources{
 		Cases: DataSource[sciensano.Cases]{
-			Fetcher:         &sciensano.Fetcher[sciensano.Cases]{Target: sciensano.MustGetURL(url, sciensano.CasesEndpoint), Client: httpClient},
+			Name:            sciensano.CasesEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.Cases](src, sciensano.CasesEndpoint),
+			Retry:           retryPolicy,
 			PollingInterval: pollingInterval,
-			Logger:          logger.With("datasource", "cases"),
+			Logger:          logger.With("datasource", sciensano.CasesEndpoint.String()),
 		},
 		Hospitalisations: DataSource[sciensano.Hospitalisations]{
-			Fetcher:         &sciensano.Fetcher[sciensano.Hospitalisations]{Target: sciensano.MustGetURL(url, sciensano.HospitalisationsEndpoint), Client: httpClient},
+			Name:            sciensano.HospitalisationsEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.Hospitalisations](src, sciensano.HospitalisationsEndpoint),
+			Retry:           retryPolicy,
 			PollingInterval: pollingInterval,
-			Logger:          logger.With("datasource", "hospitalisations"),
+			Logger:          logger.With("datasource", sciensano.HospitalisationsEndpoint.String()),
 		},
 		Mortalities: DataSource[sciensano.Mortalities]{
-			Fetcher:         &sciensano.Fetcher[sciensano.Mortalities]{Target: sciensano.MustGetURL(url, sciensano.MortalitiesEndpoint), Client: httpClient},
+			Name:            sciensano.MortalitiesEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.Mortalities](src, sciensano.MortalitiesEndpoint),
+			Retry:           retryPolicy,
 			PollingInterval: pollingInterval,
-			Logger:          logger.With("datasource", "mortalities"),
+			Logger:          logger.With("datasource", sciensano.MortalitiesEndpoint.String()),
 		},
 		TestResults: DataSource[sciensano.TestResults]{
-			Fetcher:         &sciensano.Fetcher[sciensano.TestResults]{Target: sciensano.MustGetURL(url, sciensano.TestResultsEndpoint), Client: httpClient},
+			Name:            sciensano.TestResultsEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.TestResults](src, sciensano.TestResultsEndpoint),
+			Retry:           retryPolicy,
 			PollingInterval: pollingInterval,
-			Logger:          logger.With("datasource", "testResults"),
+			Logger:          logger.With("datasource", sciensano.TestResultsEndpoint.String()),
 		},
 		Vaccinations: DataSource[sciensano.Vaccinations]{
-			Fetcher:         &sciensano.Fetcher[sciensano.Vaccinations]{Target: sciensano.MustGetURL(url, sciensano.VaccinationsEndpoint), Client: httpClient},
+			Name:            sciensano.VaccinationsEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.Vaccinations](src, sciensano.VaccinationsEndpoint),
+			Retry:           retryPolicy,
+			PollingInterval: pollingInterval,
+			Logger:          logger.With("datasource", sciensano.VaccinationsEndpoint.String()),
+		},
+		MunicipalityCases: DataSource[sciensano.MunicipalityCases]{
+			Name:            sciensano.MunicipalityCasesEndpoint.String(),
+			Fetcher:         newFetcher[sciensano.MunicipalityCases](src, sciensano.MunicipalityCasesEndpoint),
+			Retry:           retryPolicy,
 			PollingInterval: pollingInterval,
-			Logger:          logger.With("datasource", "vaccinations"),
+			Logger:          logger.With("datasource", sciensano.MunicipalityCasesEndpoint.String()),
 		},
 	}
 	_ = store.Add(&store.Cases)
@@ -53,6 +99,90 @@ func NewSciensanoDatastore(url string, pollingInterval time.Duration, httpClient
 	_ = store.Add(&store.Mortalities)
 	_ = store.Add(&store.TestResults)
 	_ = store.Add(&store.Vaccinations)
+	_ = store.Add(&store.MunicipalityCases)
 
 	return &store
 }
+
+// SetCacheDirectory makes the datasources cache the data they retrieve in dir. On startup, the datasources publish the
+// cached data before retrieving any new data. Must be called before Run.
+func (s *SciensanoSources) SetCacheDirectory(dir string) {
+	s.Cases.Cache = newFileCache[sciensano.Cases](dir, sciensano.CasesEndpoint)
+	s.Hospitalisations.Cache = newFileCache[sciensano.Hospitalisations](dir, sciensano.HospitalisationsEndpoint)
+	s.Mortalities.Cache = newFileCache[sciensano.Mortalities](dir, sciensano.MortalitiesEndpoint)
+	s.TestResults.Cache = newFileCache[sciensano.TestResults](dir, sciensano.TestResultsEndpoint)
+	s.Vaccinations.Cache = newFileCache[sciensano.Vaccinations](dir, sciensano.VaccinationsEndpoint)
+	s.MunicipalityCases.Cache = newFileCache[sciensano.MunicipalityCases](dir, sciensano.MunicipalityCasesEndpoint)
+}
+
+// SetRetryPolicy sets how the datasources retry a failed fetch. If the policy doesn't specify which errors are retryable,
+// only transient errors (as reported by sciensano.IsRetryable) are retried. Must be called before Run.
+func (s *SciensanoSources) SetRetryPolicy(policy RetryPolicy) {
+	if policy.Retryable == nil {
+		policy.Retryable = sciensano.IsRetryable
+	}
+	s.Cases.Retry = policy
+	s.Hospitalisations.Retry = policy
+	s.Mortalities.Retry = policy
+	s.TestResults.Retry = policy
+	s.Vaccinations.Retry = policy
+	s.MunicipalityCases.Retry = policy
+}
+
+// SetMetrics makes the datasources record their fetch failures and recoveries in metrics. Must be called before Run.
+func (s *SciensanoSources) SetMetrics(metrics *Metrics) {
+	s.Cases.Metrics = metrics
+	s.Hospitalisations.Metrics = metrics
+	s.Mortalities.Metrics = metrics
+	s.TestResults.Metrics = metrics
+	s.Vaccinations.Metrics = metrics
+	s.MunicipalityCases.Metrics = metrics
+}
+
+// SetUpdateLog makes the datasources record each time they publish new data in updates. Must be called before Run.
+func (s *SciensanoSources) SetUpdateLog(updates *UpdateLog) {
+	s.Cases.Updates = updates
+	s.Hospitalisations.Updates = updates
+	s.Mortalities.Updates = updates
+	s.TestResults.Updates = updates
+	s.Vaccinations.Updates = updates
+	s.MunicipalityCases.Updates = updates
+}
+
+// GetStatus returns the freshness of all datasources.
+func (s *SciensanoSources) GetStatus() []Status {
+	return []Status{
+		s.Cases.GetStatus(),
+		s.Hospitalisations.GetStatus(),
+		s.Mortalities.GetStatus(),
+		s.TestResults.GetStatus(),
+		s.Vaccinations.GetStatus(),
+		s.MunicipalityCases.GetStatus(),
+	}
+}
+
+func newFileCache[T any](dir string, endpoint sciensano.Endpoint) Cache[T] {
+	return &FileCache[T]{Path: filepath.Join(dir, endpoint.String()+".gob")}
+}
+
+// source determines where the datasources get their data: the Sciensano API (or a compatible server at url),
+// a local directory or a local tarball.
+type source struct {
+	url     string
+	client  *http.Client
+	dir     string
+	archive string
+	formats map[sciensano.Endpoint]sciensano.Format
+}
+
+func newFetcher[T any](src source, endpoint sciensano.Endpoint) Fetcher[T] {
+	format := src.formats[endpoint]
+	switch {
+	case src.archive != "":
+		return &sciensano.ArchiveFetcher[T]{Path: src.archive, Filename: sciensano.MustGetFilename(endpoint, format), Format: format}
+	case src.dir != "":
+		return &sciensano.FileFetcher[T]{Path: filepath.Join(src.dir, sciensano.MustGetFilename(endpoint, format)), Format: format}
+	default:
+		return &sciensano.Fetcher[T]{Target: sciensano.MustGetURLForFormat(src.url, endpoint, format), Client: src.client, Format: format}
+	}
+}
@@ -8,39 +8,40 @@ import (
 	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
 func TestNewSciensanoDatastore(t *testing.T) {
-	s := datasource.NewSciensanoDatastore("", time.Second, http.DefaultClient, slog.Default())
+	s := datasource.NewSciensanoDatastore("", nil, time.Second, http.DefaultClient, slog.Default())
 
 	casesFetcher := mocks.NewFetcher[sciensano.Cases](t)
-	casesFetcher.EXPECT().GetLastModified(mock.AnythingOfType("*context.cancelCtx")).Return(time.Now(), nil)
-	casesFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Cases(), nil)
+	casesFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Cases(), time.Now(), true, nil)
 	s.Cases.Fetcher = casesFetcher
 
 	hospFetcher := mocks.NewFetcher[sciensano.Hospitalisations](t)
-	hospFetcher.EXPECT().GetLastModified(mock.AnythingOfType("*context.cancelCtx")).Return(time.Now(), nil)
-	hospFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Hospitalisations(), nil)
+	hospFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Hospitalisations(), time.Now(), true, nil)
 	s.Hospitalisations.Fetcher = hospFetcher
 
 	mortFetcher := mocks.NewFetcher[sciensano.Mortalities](t)
-	mortFetcher.EXPECT().GetLastModified(mock.AnythingOfType("*context.cancelCtx")).Return(time.Now(), nil)
-	mortFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Mortalities(), nil)
+	mortFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Mortalities(), time.Now(), true, nil)
 	s.Mortalities.Fetcher = mortFetcher
 
 	testFetcher := mocks.NewFetcher[sciensano.TestResults](t)
-	testFetcher.EXPECT().GetLastModified(mock.AnythingOfType("*context.cancelCtx")).Return(time.Now(), nil)
-	testFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.TestResults(), nil)
+	testFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.TestResults(), time.Now(), true, nil)
 	s.TestResults.Fetcher = testFetcher
 
 	vaccFetcher := mocks.NewFetcher[sciensano.Vaccinations](t)
-	vaccFetcher.EXPECT().GetLastModified(mock.AnythingOfType("*context.cancelCtx")).Return(time.Now(), nil)
-	vaccFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Vaccinations(), nil)
+	vaccFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.Vaccinations(), time.Now(), true, nil)
 	s.Vaccinations.Fetcher = vaccFetcher
+	muniFetcher := mocks.NewFetcher[sciensano.MunicipalityCases](t)
+	muniFetcher.EXPECT().Fetch(mock.AnythingOfType("*context.cancelCtx")).Return(testutil.MunicipalityCases(), time.Now(), true, nil)
+	s.MunicipalityCases.Fetcher = muniFetcher
 
 	ch := make(chan sciensano.Vaccinations)
 	s.Vaccinations.Register(ch)
@@ -60,9 +61,38 @@ func TestNewSciensanoDatastore(t *testing.T) {
 			!s.Hospitalisations.GetCurrentAge().IsZero() &&
 			!s.Mortalities.GetCurrentAge().IsZero() &&
 			!s.TestResults.GetCurrentAge().IsZero() &&
-			!s.Vaccinations.GetCurrentAge().IsZero()
+			!s.Vaccinations.GetCurrentAge().IsZero() &&
+			!s.MunicipalityCases.GetCurrentAge().IsZero()
 	}, time.Minute, time.Second)
 
 	cancel()
 	assert.ErrorIs(t, <-errCh, context.Canceled)
 }
+
+func TestNewLocalSciensanoDatastore(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "COVID19BE_MORT.json"), []byte(`[{"DATE":"2024-03-01","REGION":"Flanders","AGEGROUP":"85+","DEATHS":1}]`), 0644)
+	require.NoError(t, err)
+
+	_, err = datasource.NewLocalSciensanoDatastore(filepath.Join(tmpDir, "missing"), nil, time.Hour, slog.Default())
+	require.Error(t, err)
+
+	s, err := datasource.NewLocalSciensanoDatastore(tmpDir, nil, time.Hour, slog.Default())
+	require.NoError(t, err)
+
+	ch := make(chan sciensano.Mortalities)
+	s.Mortalities.Register(ch)
+	errCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		errCh <- s.Run(ctx)
+	}()
+
+	assert.Len(t, <-ch, 1)
+	assert.False(t, s.Mortalities.GetCurrentAge().IsZero())
+	// files that don't exist are reported, but don't stop the other datasources
+	assert.True(t, s.Cases.GetCurrentAge().IsZero())
+
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+}
@@ -0,0 +1,64 @@
+package datasource
+
+import "time"
+
+// Dataset is implemented by data that can report its number of records and the date of its most recent record.
+type Dataset interface {
+	Len() int
+	LastTimestamp() time.Time
+}
+
+// Status reports the freshness of a DataSource.
+type Status struct {
+	// Name of the DataSource
+	Name string
+	// LastPoll is the time of the last successful poll of the upstream data
+	LastPoll time.Time
+	// LastModified is the modification time of the current data, as reported by the upstream source
+	LastModified time.Time
+	// LastRecord is the date of the most recent record in the current data
+	LastRecord time.Time
+	// Records is the number of records in the current data
+	Records int
+}
+
+// Stale reports whether the DataSource hasn't been polled successfully, or the upstream data hasn't been modified, for longer than threshold.
+// A zero threshold disables the check. A DataSource that hasn't been polled yet (i.e. at startup) isn't stale.
+func (s Status) Stale(threshold time.Duration, now time.Time) bool {
+	if threshold == 0 || s.LastPoll.IsZero() {
+		return false
+	}
+	return now.Sub(s.LastPoll) > threshold || now.Sub(s.LastModified) > threshold
+}
+
+// GetStatus returns the freshness of the DataSource.
+func (d *DataSource[T]) GetStatus() Status {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return Status{
+		Name:         d.Name,
+		LastPoll:     d.lastPoll,
+		LastModified: d.currentAge,
+		LastRecord:   d.lastRecord,
+		Records:      d.records,
+	}
+}
+
+// setCurrentData sets the current data and records its freshness. Must be called with the lock held.
+func (d *DataSource[T]) setCurrentData(data T, lastModified time.Time) {
+	d.currentData = data
+	d.currentAge = lastModified
+	d.records, d.lastRecord = 0, time.Time{}
+	if dataset, ok := any(data).(Dataset); ok {
+		d.records, d.lastRecord = dataset.Len(), dataset.LastTimestamp()
+	}
+	d.Metrics.data(d.Name, lastModified, d.records, d.lastRecord)
+	d.Updates.Record(Update{Name: d.Name, LastModified: lastModified, Records: d.records})
+}
+
+func (d *DataSource[T]) setLastPoll(timestamp time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.lastPoll = timestamp
+	d.Metrics.poll(d.Name, timestamp)
+}
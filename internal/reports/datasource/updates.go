@@ -0,0 +1,75 @@
+package datasource
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Update records that a DataSource published new data.
+type Update struct {
+	// Name of the DataSource
+	Name string
+	// LastModified is the modification time of the new data, as reported by the upstream source
+	LastModified time.Time
+	// Records is the number of records in the new data
+	Records int
+}
+
+// UpdateLog records the updates of one or more DataSources, ordered by modification time.
+// Only the most recent MaxUpdates updates are kept. If MaxUpdates is zero, DefaultMaxUpdates is used.
+type UpdateLog struct {
+	MaxUpdates int
+	updates    []Update
+	lock       sync.RWMutex
+}
+
+// DefaultMaxUpdates is the default number of updates kept by an UpdateLog.
+const DefaultMaxUpdates = 1000
+
+// Record adds an update to the log. An update for the same DataSource and modification time is only recorded once,
+// so the data loaded from a cache, or published again after a restart, doesn't show up as a new update.
+func (l *UpdateLog) Record(update Update) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	index, found := slices.BinarySearchFunc(l.updates, update, compareUpdates)
+	if found {
+		return
+	}
+	l.updates = slices.Insert(l.updates, index, update)
+	maxUpdates := l.MaxUpdates
+	if maxUpdates == 0 {
+		maxUpdates = DefaultMaxUpdates
+	}
+	if excess := len(l.updates) - maxUpdates; excess > 0 {
+		l.updates = slices.Delete(l.updates, 0, excess)
+	}
+}
+
+// GetUpdates returns the updates with a modification time between from and to (inclusive).
+func (l *UpdateLog) GetUpdates(from, to time.Time) []Update {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	var updates []Update
+	for _, update := range l.updates {
+		if update.LastModified.Before(from) {
+			continue
+		}
+		if update.LastModified.After(to) {
+			break
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+func compareUpdates(a, b Update) int {
+	if c := a.LastModified.Compare(b.LastModified); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Name, b.Name)
+}
@@ -0,0 +1,37 @@
+package datasource_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUpdateLog(t *testing.T) {
+	day := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	l := datasource.UpdateLog{MaxUpdates: 3}
+	l.Record(datasource.Update{Name: "cases", LastModified: day.AddDate(0, 0, 1), Records: 20})
+	l.Record(datasource.Update{Name: "cases", LastModified: day, Records: 10})
+	l.Record(datasource.Update{Name: "mortalities", LastModified: day, Records: 5})
+	// same update (e.g. published again from the cache): ignored
+	l.Record(datasource.Update{Name: "cases", LastModified: day, Records: 10})
+
+	assert.Equal(t, []datasource.Update{
+		{Name: "cases", LastModified: day, Records: 10},
+		{Name: "mortalities", LastModified: day, Records: 5},
+		{Name: "cases", LastModified: day.AddDate(0, 0, 1), Records: 20},
+	}, l.GetUpdates(day, day.AddDate(0, 0, 1)))
+	assert.Equal(t, []datasource.Update{
+		{Name: "cases", LastModified: day.AddDate(0, 0, 1), Records: 20},
+	}, l.GetUpdates(day.Add(time.Hour), day.AddDate(0, 0, 2)))
+	assert.Empty(t, l.GetUpdates(day.AddDate(0, 0, 2), day.AddDate(0, 0, 3)))
+
+	// the oldest update is dropped
+	l.Record(datasource.Update{Name: "cases", LastModified: day.AddDate(0, 0, 2), Records: 30})
+	assert.Len(t, l.GetUpdates(day, day.AddDate(0, 0, 2)), 3)
+	assert.Equal(t, []datasource.Update{{Name: "mortalities", LastModified: day, Records: 5}}, l.GetUpdates(day, day))
+
+	// a nil UpdateLog ignores updates
+	var nilLog *datasource.UpdateLog
+	nilLog.Record(datasource.Update{Name: "cases"})
+}
@@ -0,0 +1,134 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/clambin/go-common/set"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/go-common/taskmanager"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"sync"
+)
+
+// ErrNoData is returned by Records.Summarize when the datasource hasn't published any data yet.
+var ErrNoData = errors.New("no data received yet")
+
+// Records keeps the latest data of the Sciensano datasources, so queries can summarize the records that match a filter,
+// instead of being limited to the stored reports.
+type Records struct {
+	cases             latest[sciensano.Cases]
+	hospitalisations  latest[sciensano.Hospitalisations]
+	mortalities       latest[sciensano.Mortalities]
+	testResults       latest[sciensano.TestResults]
+	vaccinations      latest[sciensano.Vaccinations]
+	municipalityCases latest[sciensano.MunicipalityCases]
+}
+
+var _ taskmanager.Task = &Records{}
+
+// NewRecords creates Records for the datasources. The records are only received while Run is running.
+func NewRecords(datasources *datasource.SciensanoSources) *Records {
+	return &Records{
+		cases:             latest[sciensano.Cases]{source: &datasources.Cases},
+		hospitalisations:  latest[sciensano.Hospitalisations]{source: &datasources.Hospitalisations},
+		mortalities:       latest[sciensano.Mortalities]{source: &datasources.Mortalities},
+		testResults:       latest[sciensano.TestResults]{source: &datasources.TestResults},
+		vaccinations:      latest[sciensano.Vaccinations]{source: &datasources.Vaccinations},
+		municipalityCases: latest[sciensano.MunicipalityCases]{source: &datasources.MunicipalityCases},
+	}
+}
+
+// Run receives the data published by the datasources, until the context is canceled.
+func (r *Records) Run(ctx context.Context) error {
+	return taskmanager.New(&r.cases, &r.hospitalisations, &r.mortalities, &r.testResults, &r.vaccinations, &r.municipalityCases).Run(ctx)
+}
+
+// TagValues returns the sorted, unique values of the tag in the latest data of all datasources.
+func (r *Records) TagValues(tag sciensano.Tag) []string {
+	values := set.Create[string]()
+	values.Add(tagValues(&r.cases, tag)...)
+	values.Add(tagValues(&r.hospitalisations, tag)...)
+	values.Add(tagValues(&r.mortalities, tag)...)
+	values.Add(tagValues(&r.testResults, tag)...)
+	values.Add(tagValues(&r.vaccinations, tag)...)
+	values.Add(tagValues(&r.municipalityCases, tag)...)
+	return values.ListOrdered()
+}
+
+// Summarize summarizes the records of the datasource (e.g. cases or testResults) that match the filter, as determined by the breakdown.
+func (r *Records) Summarize(datasource string, breakdown sciensano.Breakdown, filter sciensano.Filter) (*tabulator.Tabulator, error) {
+	switch sciensano.EndpointNames[datasource] {
+	case sciensano.CasesEndpoint:
+		return summarizeRecords(&r.cases, breakdown, filter)
+	case sciensano.HospitalisationsEndpoint:
+		return summarizeRecords(&r.hospitalisations, breakdown, filter)
+	case sciensano.MortalitiesEndpoint:
+		return summarizeRecords(&r.mortalities, breakdown, filter)
+	case sciensano.TestResultsEndpoint:
+		return summarizeRecords(&r.testResults, breakdown, filter)
+	case sciensano.VaccinationsEndpoint:
+		return summarizeRecords(&r.vaccinations, breakdown, filter)
+	case sciensano.MunicipalityCasesEndpoint:
+		return summarizeRecords(&r.municipalityCases, breakdown, filter)
+	default:
+		return nil, fmt.Errorf("invalid datasource: %s", datasource)
+	}
+}
+
+type taggedRecords[E sciensano.Tagged] interface {
+	~[]E
+	summarizer
+}
+
+func summarizeRecords[S taggedRecords[E], E sciensano.Tagged](l *latest[S], breakdown sciensano.Breakdown, filter sciensano.Filter) (*tabulator.Tabulator, error) {
+	if err := sciensano.ValidateFilter[E](filter); err != nil {
+		return nil, err
+	}
+	data, ok := l.get()
+	if !ok {
+		return nil, ErrNoData
+	}
+	return sciensano.SummarizeBreakdown(sciensano.FilterRecords(data, filter), breakdown)
+}
+
+func tagValues[S taggedRecords[E], E sciensano.Tagged](l *latest[S], tag sciensano.Tag) []string {
+	data, _ := l.get()
+	return sciensano.TagValues(data, tag)
+}
+
+// latest keeps the most recent data published by a source.
+type latest[T any] struct {
+	source   reporter.Publisher[T]
+	data     T
+	received bool
+	lock     sync.RWMutex
+}
+
+func (l *latest[T]) Run(ctx context.Context) error {
+	ch := make(chan T)
+	l.source.Register(ch)
+	defer func() {
+		l.source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			l.lock.Lock()
+			l.data, l.received = data, true
+			l.lock.Unlock()
+		}
+	}
+}
+
+func (l *latest[T]) get() (T, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.data, l.received
+}
@@ -0,0 +1,66 @@
+package reports_test
+
+import (
+	"context"
+	"github.com/clambin/go-common/taskmanager"
+	"github.com/clambin/sciensano/v2/internal/reports"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecords(t *testing.T) {
+	server := testutil.NewTestServer()
+	defer server.Close()
+	datasources := datasource.NewSciensanoDatastore(server.URL, nil, time.Hour, http.DefaultClient, slog.Default())
+	records := reports.NewRecords(datasources)
+
+	_, err := records.Summarize("cases", sciensano.Breakdown{}, nil)
+	assert.ErrorIs(t, err, reports.ErrNoData)
+	_, err = records.Summarize("foo", sciensano.Breakdown{}, nil)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan error)
+	go func() { ch <- taskmanager.New(records, datasources).Run(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		_, errCases := records.Summarize("cases", sciensano.Breakdown{}, nil)
+		_, errVaccinations := records.Summarize("vaccinations", sciensano.Breakdown{}, nil)
+		return errCases == nil && errVaccinations == nil
+	}, time.Minute, 100*time.Millisecond)
+
+	assert.Contains(t, records.TagValues(sciensano.RegionTag), "Flanders")
+	assert.Contains(t, records.TagValues(sciensano.DoseTag), sciensano.Full.String())
+
+	all, err := records.Summarize("cases", sciensano.Breakdown{Primary: sciensano.ByRegion}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, all.GetColumns(), "Wallonia")
+
+	flanders, err := records.Summarize("cases", sciensano.Breakdown{Primary: sciensano.ByRegion}, sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Flanders"}, flanders.GetColumns())
+	want, _ := all.GetValues("Flanders")
+	got, _ := flanders.GetValues("Flanders")
+	assert.Equal(t, want, got)
+
+	byAgeGroup, err := records.Summarize("cases", sciensano.Breakdown{Primary: sciensano.ByRegion, Secondary: sciensano.ByAgeGroup}, sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}})
+	require.NoError(t, err)
+	for _, column := range byAgeGroup.GetColumns() {
+		assert.True(t, strings.HasPrefix(column, "Flanders"+sciensano.BreakdownSeparator), column)
+	}
+
+	// cases don't have a manufacturer
+	_, err = records.Summarize("cases", sciensano.Breakdown{Primary: sciensano.ByRegion}, sciensano.Filter{{Tag: sciensano.ManufacturerTag, Value: "Moderna"}})
+	assert.Error(t, err)
+
+	cancel()
+	assert.ErrorIs(t, <-ch, context.Canceled)
+}
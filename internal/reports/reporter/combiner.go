@@ -0,0 +1,121 @@
+package reporter
+
+import (
+	"context"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"log/slog"
+)
+
+// Combiner creates a report from several datasources. It subscribes to the Source of each Input and calls Combine
+// when all inputs have received data and whenever any of them receives new data. Combine reads the data through Input.Latest.
+type Combiner struct {
+	Name    string
+	Inputs  []CombinerInput
+	Combine func() (*tabulator.Tabulator, error)
+	Store   *store.Store
+	Logger  *slog.Logger
+}
+
+// CombinerInput is an input of a Combiner. It is implemented by Input.
+type CombinerInput interface {
+	subscribe(ctx context.Context, updates chan<- func()) (unsubscribe func())
+	ready() bool
+}
+
+var _ Reporter = &Combiner{}
+
+func (c *Combiner) GetName() string {
+	return c.Name
+}
+
+func (c *Combiner) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	updates := make(chan func())
+	unsubscribers := make([]func(), 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		unsubscribers = append(unsubscribers, input.subscribe(ctx, updates))
+	}
+	defer func() {
+		cancel()
+		for _, unsubscribe := range unsubscribers {
+			unsubscribe()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update := <-updates:
+			update()
+			if c.ready() {
+				c.createReport()
+			}
+		}
+	}
+}
+
+func (c *Combiner) ready() bool {
+	for _, input := range c.Inputs {
+		if !input.ready() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Combiner) createReport() {
+	report, err := c.Combine()
+	if err != nil {
+		c.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	c.Store.Put(c.Name, report)
+}
+
+var _ CombinerInput = &Input[int]{}
+
+// Input is a typed input of a Combiner.
+type Input[T any] struct {
+	Source   Publisher[T]
+	latest   T
+	received bool
+}
+
+// Latest returns the most recent data published by the Input's Source.
+func (i *Input[T]) Latest() T {
+	return i.latest
+}
+
+// subscribe registers with the Source and forwards its data to the Combiner. Data is only stored in the Input when
+// the Combiner runs the update, so Combine can read all inputs without locking.
+func (i *Input[T]) subscribe(ctx context.Context, updates chan<- func()) func() {
+	ch := make(chan T)
+	i.Source.Register(ch)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data := <-ch:
+				select {
+				case <-ctx.Done():
+					return
+				case updates <- func() { i.latest, i.received = data, true }:
+				}
+			}
+		}
+	}()
+	return func() {
+		<-done
+		i.Source.Unregister(ch)
+		close(ch)
+	}
+}
+
+func (i *Input[T]) ready() bool {
+	return i.received
+}
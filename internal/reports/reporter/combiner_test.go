@@ -0,0 +1,87 @@
+package reporter_test
+
+import (
+	"context"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCombiner(t *testing.T) {
+	intChCh := make(chan chan int)
+	ints := mocks.NewPublisher[int](t)
+	ints.EXPECT().Register(mock.AnythingOfType("chan int")).Run(func(ch chan int) { intChCh <- ch })
+	ints.EXPECT().Unregister(mock.AnythingOfType("chan int"))
+
+	floatChCh := make(chan chan float64)
+	floats := mocks.NewPublisher[float64](t)
+	floats.EXPECT().Register(mock.AnythingOfType("chan float64")).Run(func(ch chan float64) { floatChCh <- ch })
+	floats.EXPECT().Unregister(mock.AnythingOfType("chan float64"))
+
+	stringChCh := make(chan chan string)
+	strings := mocks.NewPublisher[string](t)
+	strings.EXPECT().Register(mock.AnythingOfType("chan string")).Run(func(ch chan string) { stringChCh <- ch })
+	strings.EXPECT().Unregister(mock.AnythingOfType("chan string"))
+
+	type combined struct {
+		i int
+		f float64
+		s string
+	}
+	results := make(chan combined)
+
+	intInput := &reporter.Input[int]{Source: ints}
+	floatInput := &reporter.Input[float64]{Source: floats}
+	stringInput := &reporter.Input[string]{Source: strings}
+	c := reporter.Combiner{
+		Name:   "combined",
+		Inputs: []reporter.CombinerInput{intInput, floatInput, stringInput},
+		Combine: func() (*tabulator.Tabulator, error) {
+			results <- combined{i: intInput.Latest(), f: floatInput.Latest(), s: stringInput.Latest()}
+			return tabulator.New(stringInput.Latest()), nil
+		},
+		Store:  &store.Store{Logger: slog.Default().With("component", "store")},
+		Logger: slog.Default().With("reporter", "combined"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan error)
+	go func() {
+		ch <- c.Run(ctx)
+	}()
+
+	intCh := <-intChCh
+	floatCh := <-floatChCh
+	stringCh := <-stringChCh
+
+	// no report until all inputs have received data
+	intCh <- 1
+	floatCh <- 2
+	select {
+	case result := <-results:
+		t.Fatalf("unexpected report: %v", result)
+	case <-time.After(100 * time.Millisecond):
+	}
+	stringCh <- "A"
+	assert.Equal(t, combined{i: 1, f: 2, s: "A"}, <-results)
+
+	// any new data recomputes the report
+	floatCh <- 3
+	assert.Equal(t, combined{i: 1, f: 3, s: "A"}, <-results)
+	stringCh <- "B"
+	assert.Equal(t, combined{i: 1, f: 3, s: "B"}, <-results)
+
+	assert.Eventually(t, func() bool {
+		report, err := c.Store.Get("combined")
+		return err == nil && len(report.GetColumns()) == 1 && report.GetColumns()[0] == "B"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-ch, context.Canceled)
+}
@@ -0,0 +1,126 @@
+package reporter
+
+import "math"
+
+// gammaCDF returns the cumulative distribution function of the gamma distribution with the given shape and scale, at x.
+// It returns NaN if the result can't be calculated with sufficient precision.
+func gammaCDF(x, shape, scale float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return regularizedGammaP(shape, x/scale)
+}
+
+const (
+	gammaQuantileEpsilon = 1e-12
+	gammaQuantileMaxIter = 100
+)
+
+// gammaQuantile returns the value x for which gammaCDF(x, shape, scale) equals p. It returns NaN if the quantile can't
+// be determined with sufficient precision.
+//
+// The quantile is found with Newton's method, starting from the Wilson–Hilferty approximation, which is already
+// accurate for large shapes. Newton's method usually converges within a few iterations.
+func gammaQuantile(p, shape, scale float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	lg, _ := math.Lgamma(shape)
+	x := gammaQuantileGuess(p, shape, lg)
+	for range gammaQuantileMaxIter {
+		cdf := regularizedGammaP(shape, x)
+		if math.IsNaN(cdf) {
+			return math.NaN()
+		}
+		pdf := math.Exp(-x + (shape-1)*math.Log(x) - lg)
+		if pdf == 0 || math.IsInf(pdf, 0) {
+			return math.NaN()
+		}
+		step := (cdf - p) / pdf
+		next := x - step
+		// the CDF is concave beyond the mode, so a step may overshoot below zero: halve x instead
+		if next <= 0 {
+			next = x / 2
+		}
+		if math.Abs(next-x) <= gammaQuantileEpsilon*x {
+			return scale * next
+		}
+		x = next
+	}
+	return math.NaN()
+}
+
+// gammaQuantileGuess returns an initial estimate of the quantile p of the gamma distribution with the given shape and
+// a scale of 1. lg is the logarithm of Γ(shape).
+func gammaQuantileGuess(p, shape, lg float64) float64 {
+	// Wilson–Hilferty: (x/shape)^(1/3) is approximately normally distributed
+	z := math.Sqrt2 * math.Erfinv(2*p-1)
+	v := 1 / (9 * shape)
+	if x := shape * math.Pow(1-v+z*math.Sqrt(v), 3); x > 0 && shape >= 1 {
+		return x
+	}
+	// for small shapes and quantiles, P(shape, x) ≈ x^shape / (shape.Γ(shape))
+	return math.Exp((math.Log(p) + math.Log(shape) + lg) / shape)
+}
+
+const (
+	gammaEpsilon  = 1e-14
+	gammaMinFloat = 1e-300
+	gammaMaxIter  = 1000
+)
+
+// regularizedGammaP returns the regularized lower incomplete gamma function P(a, x). See Numerical Recipes, chapter 6.2.
+//
+// Close to x = a, both the series and the continued fraction need O(√a) iterations, so the maximum number of iterations
+// grows with a. If the result still hasn't converged, NaN is returned.
+func regularizedGammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+	maxIter := gammaMaxIter + int(10*math.Sqrt(a))
+
+	if x < a+1 {
+		// series representation
+		ap, del := a, 1/a
+		sum := del
+		for range maxIter {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*gammaEpsilon {
+				return sum * prefix
+			}
+		}
+		return math.NaN()
+	}
+
+	// continued fraction representation of Q(a, x), evaluated with Lentz's method
+	b := x + 1 - a
+	c := 1 / gammaMinFloat
+	d := 1 / b
+	h := d
+	for i := 1; i <= maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < gammaMinFloat {
+			d = gammaMinFloat
+		}
+		c = b + an/c
+		if math.Abs(c) < gammaMinFloat {
+			c = gammaMinFloat
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < gammaEpsilon {
+			return 1 - prefix*h
+		}
+	}
+	return math.NaN()
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func Test_gammaCDF(t *testing.T) {
+	testCases := []struct {
+		x, shape, scale float64
+		want            float64
+	}{
+		{x: -1, shape: 1, scale: 1, want: 0},
+		{x: 1, shape: 1, scale: 1, want: 1 - math.Exp(-1)},
+		{x: 2, shape: 2, scale: 1, want: 1 - 3*math.Exp(-2)},
+		{x: 4, shape: 2, scale: 2, want: 1 - 3*math.Exp(-2)},
+		{x: 20, shape: 3, scale: 1, want: 1 - math.Exp(-20)*(1+20+200)},
+	}
+	for _, tt := range testCases {
+		assert.InDelta(t, tt.want, gammaCDF(tt.x, tt.shape, tt.scale), 1e-10)
+	}
+}
+
+func Test_gammaQuantile(t *testing.T) {
+	assert.Zero(t, gammaQuantile(0, 1, 1))
+	assert.InDelta(t, math.Ln2, gammaQuantile(0.5, 1, 1), 1e-9)
+	assert.InDelta(t, 2*math.Ln2, gammaQuantile(0.5, 1, 2), 1e-9)
+	assert.True(t, math.IsInf(gammaQuantile(1, 1, 1), 1))
+	for _, shape := range []float64{0.1, 0.5, 1, 5, 100, 10000, 1e6} {
+		for _, p := range []float64{1e-6, 0.025, 0.5, 0.975, 1 - 1e-6} {
+			q := gammaQuantile(p, shape, 0.1)
+			require.False(t, math.IsNaN(q), "shape: %f, p: %f", shape, p)
+			assert.InDelta(t, p, gammaCDF(q, shape, 0.1), 1e-9, "shape: %f, p: %f", shape, p)
+		}
+	}
+}
+
+func Test_regularizedGammaP(t *testing.T) {
+	// for large shapes, P(a, a) approaches 1/2
+	for _, a := range []float64{1e4, 1e6, 1e8} {
+		assert.InDelta(t, 0.5, regularizedGammaP(a, a), 0.01)
+		assert.InDelta(t, 0.5, regularizedGammaP(a, a+2), 0.01)
+	}
+	// results that can't be calculated precisely aren't returned
+	assert.True(t, math.IsNaN(regularizedGammaP(math.MaxFloat64, math.MaxFloat64/2)))
+}
+
+func Benchmark_gammaQuantile(b *testing.B) {
+	for range b.N {
+		for _, shape := range []float64{1, 100, 10000} {
+			_ = gammaQuantile(rtLowQuantile, shape, 0.1)
+			_ = gammaQuantile(rtHighQuantile, shape, 0.1)
+		}
+	}
+}
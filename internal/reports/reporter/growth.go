@@ -0,0 +1,128 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+	"math"
+)
+
+// GrowthMetric determines how the Growth reporter reports the growth of a summary
+type GrowthMetric int
+
+const (
+	// WeeklyGrowth reports the week-over-week growth, as a percentage
+	WeeklyGrowth GrowthMetric = iota
+	// DoublingTime reports the number of days for the figures to double. A negative value is the number of days for the figures to halve.
+	DoublingTime
+)
+
+// Growth summarizes the data of a datasource and stores its growth, estimated by a log-linear fit over a sliding window of Window days.
+// Window must be at least 2 days.
+type Growth[T summarizer] struct {
+	Name   string
+	Source Publisher[T]
+	// Summarize summarizes the data whose growth is reported. If not set, T's Summarize method is used.
+	Summarize func(T, sciensano.SummaryColumn) (*tabulator.Tabulator, error)
+	Mode      sciensano.SummaryColumn
+	Metric    GrowthMetric
+	Window    int
+	Store     *store.Store
+	Logger    *slog.Logger
+}
+
+var _ Reporter = &Growth[sciensano.Cases]{}
+
+func (g *Growth[T]) GetName() string {
+	return g.Name
+}
+
+func (g *Growth[T]) Run(ctx context.Context) error {
+	ch := make(chan T)
+	g.Source.Register(ch)
+	defer func() {
+		g.Source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			g.createReport(data)
+		}
+	}
+}
+
+func (g *Growth[T]) createReport(data T) {
+	if g.Window < MinGrowthWindow {
+		g.Logger.Error("failed to generate report", "err", fmt.Errorf("invalid window: %d", g.Window))
+		return
+	}
+	summarize := T.Summarize
+	if g.Summarize != nil {
+		summarize = g.Summarize
+	}
+	summary, err := summarize(data, g.Mode)
+	if err != nil {
+		g.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	g.Store.Put(g.Name, growth(summary, g.Window, g.Metric))
+}
+
+// MinGrowthWindow is the smallest window over which a growth rate can be estimated
+const MinGrowthWindow = 2
+
+const minGrowthRate = 1e-9
+
+func growth(summary *tabulator.Tabulator, window int, metric GrowthMetric) *tabulator.Tabulator {
+	days, values := dailyIncidence(summary)
+	columns := summary.GetColumns()
+	t := tabulator.New(columns...)
+	for _, column := range columns {
+		for day := window - 1; day < len(days); day++ {
+			rate, ok := logLinearGrowth(values[column][day-window+1 : day+1])
+			// rounding errors mean flat figures may have a tiny, non-zero growth rate
+			if !ok || math.Abs(rate) < minGrowthRate {
+				continue
+			}
+			switch metric {
+			case WeeklyGrowth:
+				t.Set(days[day], column, 100*(math.Exp(7*rate)-1))
+			case DoublingTime:
+				t.Set(days[day], column, math.Ln2/rate)
+			}
+		}
+	}
+	return t
+}
+
+// logLinearGrowth returns the daily growth rate r of the values, by fitting ln(value) = a + r.day with least squares.
+// Days without a (positive) value are ignored. If less than half of the days have a value, no growth rate is returned.
+func logLinearGrowth(values []float64) (float64, bool) {
+	var n, sumX, sumY, sumXX, sumXY float64
+	for day, value := range values {
+		if value <= 0 {
+			continue
+		}
+		x, y := float64(day), math.Log(value)
+		n++
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+	if n < 2 || n < float64(len(values))/2 {
+		return 0, false
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator, true
+}
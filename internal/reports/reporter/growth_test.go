@@ -0,0 +1,124 @@
+package reporter
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_logLinearGrowth(t *testing.T) {
+	testCases := []struct {
+		name   string
+		values []float64
+		want   float64
+		wantOK bool
+	}{
+		{name: "constant", values: []float64{10, 10, 10, 10}, want: 0, wantOK: true},
+		{name: "doubling", values: []float64{1, 2, 4, 8, 16}, want: math.Ln2, wantOK: true},
+		{name: "halving", values: []float64{16, 8, 0, 2, 1}, want: -math.Ln2, wantOK: true},
+		{name: "too few values", values: []float64{0, 0, 0, 8, 16}, wantOK: false},
+		{name: "empty", wantOK: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := logLinearGrowth(tt.values)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want, rate, 1e-9)
+		})
+	}
+}
+
+func Test_growth(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	summary := tabulator.New("Growing", "Shrinking", "Flat")
+	for day := range 21 {
+		ts := start.AddDate(0, 0, day)
+		// doubles every week
+		summary.Set(ts, "Growing", 100*math.Pow(2, float64(day)/7))
+		// halves every 10 days
+		summary.Set(ts, "Shrinking", 1000*math.Pow(0.5, float64(day)/10))
+		summary.Set(ts, "Flat", 50)
+	}
+
+	weekly := growth(summary, 7, WeeklyGrowth)
+	assert.Equal(t, 21-6, weekly.Size())
+	values, _ := weekly.GetValues("Growing")
+	assert.InDelta(t, 100, values[len(values)-1], 1e-6)
+
+	doubling := growth(summary, 7, DoublingTime)
+	values, _ = doubling.GetValues("Growing")
+	assert.InDelta(t, 7, values[len(values)-1], 1e-6)
+	values, _ = doubling.GetValues("Shrinking")
+	assert.InDelta(t, -10, values[len(values)-1], 1e-6)
+	// flat figures don't have a doubling time
+	values, _ = doubling.GetValues("Flat")
+	assert.Zero(t, values[len(values)-1])
+}
+
+func TestGrowth_createReport(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var mortalities sciensano.Mortalities
+	for day := range 14 {
+		mortalities = append(mortalities, sciensano.Mortality{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Region: "Flanders", Deaths: 10 + day})
+	}
+
+	r := Growth[sciensano.Mortalities]{
+		Name:   "mortalities-ByRegion-growth",
+		Mode:   sciensano.ByRegion,
+		Metric: WeeklyGrowth,
+		Window: 7,
+		Store:  &store.Store{Logger: slog.Default()},
+		Logger: slog.Default(),
+	}
+	r.createReport(mortalities)
+
+	report, err := r.Store.Get("mortalities-ByRegion-growth")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Flanders"}, report.GetColumns())
+	values, _ := report.GetValues("Flanders")
+	for _, value := range values {
+		assert.Positive(t, value)
+	}
+}
+
+func TestGrowth_createReport_Summarize(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var hospitalisations sciensano.Hospitalisations
+	for day := range 14 {
+		// admissions grow, while the number of patients in hospital drops
+		hospitalisations = append(hospitalisations, sciensano.Hospitalisation{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, NewIn: 10 + day, TotalIn: 100 - day})
+	}
+
+	r := Growth[sciensano.Hospitalisations]{
+		Name:      "hospitalisations-Total-growth",
+		Summarize: sciensano.Hospitalisations.SummarizeAdmissions,
+		Mode:      sciensano.Total,
+		Metric:    WeeklyGrowth,
+		Window:    7,
+		Store:     &store.Store{Logger: slog.Default()},
+		Logger:    slog.Default(),
+	}
+	r.createReport(hospitalisations)
+
+	report, err := r.Store.Get("hospitalisations-Total-growth")
+	require.NoError(t, err)
+	values, _ := report.GetValues("Total")
+	require.NotEmpty(t, values)
+	for _, value := range values {
+		assert.Positive(t, value)
+	}
+
+	// the window must be at least 2 days
+	r.Name = "hospitalisations-Total-growth-invalid"
+	r.Window = -1
+	r.createReport(hospitalisations)
+	_, err = r.Store.Get(r.Name)
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"context"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+)
+
+// Incidence summarizes the data of a datasource and stores it as a rate per 100,000 people.
+// If Days is set, it stores the cumulative incidence over the last Days days instead of the daily rate.
+type Incidence[T summarizer] struct {
+	Name   string
+	Source Publisher[T]
+	// Summarize summarizes the new daily figures of the data. If not set, T's Summarize method is used.
+	Summarize func(T, sciensano.SummaryColumn) (*tabulator.Tabulator, error)
+	Mode      sciensano.SummaryColumn
+	Days      int
+	PopStore  PopulationFetcher
+	Store     *store.Store
+	Logger    *slog.Logger
+}
+
+var _ Reporter = &Incidence[sciensano.Cases]{}
+
+func (i *Incidence[T]) GetName() string {
+	return i.Name
+}
+
+func (i *Incidence[T]) Run(ctx context.Context) error {
+	ch := make(chan T)
+	i.Source.Register(ch)
+	defer func() {
+		i.Source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			i.createReport(data)
+		}
+	}
+}
+
+func (i *Incidence[T]) createReport(data T) {
+	summarize := T.Summarize
+	if i.Summarize != nil {
+		summarize = i.Summarize
+	}
+	summarized, err := summarize(data, i.Mode)
+	if err != nil {
+		i.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	if i.Days > 0 {
+		summarized = cumulate(summarized, i.Days)
+	}
+	rated, err := proRate(summarized, i.Mode, i.PopStore, 100_000)
+	if err != nil {
+		i.Logger.Error("failed to generate incidence report", "err", err)
+		return
+	}
+	i.Store.Put(i.Name, rated)
+}
+
+// cumulate replaces each value by the sum of the values of the last days days, up to and including the value's day.
+func cumulate(t *tabulator.Tabulator, days int) *tabulator.Tabulator {
+	timestamps := t.GetTimestamps()
+	columns := t.GetColumns()
+	cumulated := tabulator.New(columns...)
+	for _, column := range columns {
+		values, _ := t.GetValues(column)
+		for index, timestamp := range timestamps {
+			from := timestamp.AddDate(0, 0, -days)
+			var total float64
+			for j := index; j >= 0 && timestamps[j].After(from); j-- {
+				total += values[j]
+			}
+			cumulated.Set(timestamp, column, total)
+		}
+	}
+	return cumulated
+}
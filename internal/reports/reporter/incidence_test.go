@@ -0,0 +1,121 @@
+package reporter
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestIncidence_createReport(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var cases sciensano.Cases
+	for day := range 20 {
+		cases = append(cases,
+			sciensano.Case{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Province: "Antwerpen", Cases: 10},
+			sciensano.Case{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Province: "Limburg", Cases: 5},
+		)
+	}
+
+	testCases := []struct {
+		name string
+		days int
+		want map[string][]float64
+	}{
+		{
+			name: "daily",
+			want: map[string][]float64{
+				"Antwerpen": {10, 10, 10},
+				"Limburg":   {10, 10, 10},
+			},
+		},
+		{
+			name: "14 days",
+			days: 14,
+			want: map[string][]float64{
+				"Antwerpen": {10, 20, 30},
+				"Limburg":   {10, 20, 30},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mocks.NewPopulationFetcher(t)
+			p.EXPECT().WaitTillReady(mock.Anything).Return(nil)
+			p.EXPECT().GetForProvince("Antwerpen").Return(100_000)
+			p.EXPECT().GetForProvince("Limburg").Return(50_000)
+
+			r := Incidence[sciensano.Cases]{
+				Name:     "cases-per100k-ByProvince",
+				Mode:     sciensano.ByProvince,
+				Days:     tt.days,
+				PopStore: p,
+				Store:    &store.Store{Logger: slog.Default()},
+				Logger:   slog.Default(),
+			}
+			r.createReport(cases)
+
+			report, err := r.Store.Get("cases-per100k-ByProvince")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Antwerpen", "Limburg"}, report.GetColumns())
+			for column, want := range tt.want {
+				values, ok := report.GetValues(column)
+				require.True(t, ok)
+				require.Len(t, values, 20)
+				assert.Equal(t, want, values[:3])
+				if tt.days > 0 {
+					assert.Equal(t, want[0]*float64(tt.days), values[19])
+				}
+			}
+		})
+	}
+}
+
+func TestCumulate(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	table := tabulator.New("A")
+	table.Set(start, "A", 1)
+	table.Set(start.AddDate(0, 0, 1), "A", 2)
+	// no data on day 2
+	table.Set(start.AddDate(0, 0, 3), "A", 4)
+	table.Set(start.AddDate(0, 0, 4), "A", 8)
+
+	values, _ := cumulate(table, 3).GetValues("A")
+	assert.Equal(t, []float64{1, 3, 6, 12}, values)
+}
+
+func TestIncidence_createReport_Summarize(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var hospitalisations sciensano.Hospitalisations
+	for day := range 3 {
+		hospitalisations = append(hospitalisations, sciensano.Hospitalisation{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Province: "Antwerpen", NewIn: 10, TotalIn: 100})
+	}
+
+	p := mocks.NewPopulationFetcher(t)
+	p.EXPECT().WaitTillReady(mock.Anything).Return(nil)
+	p.EXPECT().GetForProvince("Antwerpen").Return(100_000)
+
+	r := Incidence[sciensano.Hospitalisations]{
+		Name:      "hospitalisations-ByProvince-per100k-14d",
+		Summarize: sciensano.Hospitalisations.SummarizeAdmissions,
+		Mode:      sciensano.ByProvince,
+		Days:      14,
+		PopStore:  p,
+		Store:     &store.Store{Logger: slog.Default()},
+		Logger:    slog.Default(),
+	}
+	r.createReport(hospitalisations)
+
+	// the incidence is based on new admissions, not on the number of patients in hospital
+	report, err := r.Store.Get(r.Name)
+	require.NoError(t, err)
+	values, _ := report.GetValues("Antwerpen")
+	assert.Equal(t, []float64{10, 20, 30}, values)
+}
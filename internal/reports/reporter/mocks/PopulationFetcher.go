@@ -65,6 +65,90 @@ func (_c *PopulationFetcher_GetForAgeBracket_Call) RunAndReturn(run func(bracket
 	return _c
 }
 
+// GetForGender provides a mock function with given fields: gender
+func (_m *PopulationFetcher) GetForGender(gender string) int {
+	ret := _m.Called(gender)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(gender)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// PopulationFetcher_GetForGender_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetForGender'
+type PopulationFetcher_GetForGender_Call struct {
+	*mock.Call
+}
+
+// GetForGender is a helper method to define mock.On call
+//   - gender string
+func (_e *PopulationFetcher_Expecter) GetForGender(gender interface{}) *PopulationFetcher_GetForGender_Call {
+	return &PopulationFetcher_GetForGender_Call{Call: _e.mock.On("GetForGender", gender)}
+}
+
+func (_c *PopulationFetcher_GetForGender_Call) Run(run func(gender string)) *PopulationFetcher_GetForGender_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *PopulationFetcher_GetForGender_Call) Return(count int) *PopulationFetcher_GetForGender_Call {
+	_c.Call.Return(count)
+	return _c
+}
+
+func (_c *PopulationFetcher_GetForGender_Call) RunAndReturn(run func(string) int) *PopulationFetcher_GetForGender_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetForProvince provides a mock function with given fields: province
+func (_m *PopulationFetcher) GetForProvince(province string) int {
+	ret := _m.Called(province)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(province)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// PopulationFetcher_GetForProvince_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetForProvince'
+type PopulationFetcher_GetForProvince_Call struct {
+	*mock.Call
+}
+
+// GetForProvince is a helper method to define mock.On call
+//   - province string
+func (_e *PopulationFetcher_Expecter) GetForProvince(province interface{}) *PopulationFetcher_GetForProvince_Call {
+	return &PopulationFetcher_GetForProvince_Call{Call: _e.mock.On("GetForProvince", province)}
+}
+
+func (_c *PopulationFetcher_GetForProvince_Call) Run(run func(province string)) *PopulationFetcher_GetForProvince_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *PopulationFetcher_GetForProvince_Call) Return(count int) *PopulationFetcher_GetForProvince_Call {
+	_c.Call.Return(count)
+	return _c
+}
+
+func (_c *PopulationFetcher_GetForProvince_Call) RunAndReturn(run func(string) int) *PopulationFetcher_GetForProvince_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetForRegion provides a mock function with given fields: region
 func (_m *PopulationFetcher) GetForRegion(region string) int {
 	ret := _m.Called(region)
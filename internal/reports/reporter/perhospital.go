@@ -0,0 +1,50 @@
+package reporter
+
+import (
+	"context"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+)
+
+// PerHospital stores the hospitalisations, divided by the number of hospitals that reported figures that day.
+type PerHospital struct {
+	Name   string
+	Source Publisher[sciensano.Hospitalisations]
+	Mode   sciensano.SummaryColumn
+	Store  *store.Store
+	Logger *slog.Logger
+}
+
+var _ Reporter = &PerHospital{}
+
+func (p *PerHospital) GetName() string {
+	return p.Name
+}
+
+func (p *PerHospital) Run(ctx context.Context) error {
+	ch := make(chan sciensano.Hospitalisations)
+	p.Source.Register(ch)
+	defer func() {
+		p.Source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			p.createReport(data)
+		}
+	}
+}
+
+func (p *PerHospital) createReport(hospitalisations sciensano.Hospitalisations) {
+	report, err := hospitalisations.SummarizePerHospital(p.Mode)
+	if err != nil {
+		p.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	p.Store.Put(p.Name, report)
+}
@@ -0,0 +1,51 @@
+package reporter_test
+
+import (
+	"context"
+	"errors"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestPerHospital(t *testing.T) {
+	dataChCh := make(chan chan sciensano.Hospitalisations)
+
+	p := mocks.NewPublisher[sciensano.Hospitalisations](t)
+	p.EXPECT().Register(mock.AnythingOfType("chan sciensano.Hospitalisations")).Run(func(ch chan sciensano.Hospitalisations) {
+		dataChCh <- ch
+	})
+	p.EXPECT().Unregister(mock.AnythingOfType("chan sciensano.Hospitalisations"))
+
+	r := reporter.PerHospital{
+		Name:   "hospitalisations-ByRegion-per-hospital",
+		Source: p,
+		Mode:   sciensano.ByRegion,
+		Store:  &store.Store{Logger: slog.Default().With("component", "store")},
+		Logger: slog.Default().With("reporter", "hospitalisations-ByRegion-per-hospital"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan error)
+	go func() {
+		ch <- r.Run(ctx)
+	}()
+
+	dataCh := <-dataChCh
+	dataCh <- testutil.Hospitalisations()
+
+	assert.Eventually(t, func() bool {
+		_, err := r.Store.Get("hospitalisations-ByRegion-per-hospital")
+		return !errors.Is(err, store.ErrNotFound)
+	}, time.Minute, time.Second)
+
+	cancel()
+	assert.ErrorIs(t, <-ch, context.Canceled)
+}
@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"context"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+)
+
+// PositivityRate stores the share of positive tests, over a rolling window of Days days.
+type PositivityRate struct {
+	Name   string
+	Source Publisher[sciensano.TestResults]
+	Mode   sciensano.SummaryColumn
+	Days   int
+	Store  *store.Store
+	Logger *slog.Logger
+}
+
+var _ Reporter = &PositivityRate{}
+
+func (p *PositivityRate) GetName() string {
+	return p.Name
+}
+
+func (p *PositivityRate) Run(ctx context.Context) error {
+	ch := make(chan sciensano.TestResults)
+	p.Source.Register(ch)
+	defer func() {
+		p.Source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			p.createReport(data)
+		}
+	}
+}
+
+func (p *PositivityRate) createReport(testResults sciensano.TestResults) {
+	rates, err := testResults.PositivityRate(p.Mode, p.Days)
+	if err != nil {
+		p.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	p.Store.Put(p.Name, rates)
+}
@@ -0,0 +1,52 @@
+package reporter_test
+
+import (
+	"context"
+	"errors"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestPositivityRate(t *testing.T) {
+	dataChCh := make(chan chan sciensano.TestResults)
+
+	p := mocks.NewPublisher[sciensano.TestResults](t)
+	p.EXPECT().Register(mock.AnythingOfType("chan sciensano.TestResults")).Run(func(ch chan sciensano.TestResults) {
+		dataChCh <- ch
+	})
+	p.EXPECT().Unregister(mock.AnythingOfType("chan sciensano.TestResults"))
+
+	r := reporter.PositivityRate{
+		Name:   "positivity-rate-ByRegion",
+		Source: p,
+		Mode:   sciensano.ByRegion,
+		Days:   7,
+		Store:  &store.Store{Logger: slog.Default().With("component", "store")},
+		Logger: slog.Default().With("reporter", "positivity-rate-ByRegion"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan error)
+	go func() {
+		ch <- r.Run(ctx)
+	}()
+
+	dataCh := <-dataChCh
+	dataCh <- testutil.TestResults()
+
+	assert.Eventually(t, func() bool {
+		_, err := r.Store.Get("positivity-rate-ByRegion")
+		return !errors.Is(err, store.ErrNotFound)
+	}, time.Minute, time.Second)
+
+	cancel()
+	assert.ErrorIs(t, <-ch, context.Canceled)
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/clambin/sciensano/v2/internal/population/bracket"
 	"github.com/clambin/sciensano/v2/internal/reports/store"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"iter"
 	"log/slog"
+	"slices"
 	"time"
 )
 
@@ -28,12 +30,22 @@ type PopulationFetcher interface {
 	GetForAgeBracket(bracket bracket.Bracket) (count int)
 	// GetForRegion returns the population region
 	GetForRegion(region string) (count int)
+	// GetForProvince returns the population for the specified province
+	GetForProvince(province string) (count int)
+	// GetForGender returns the population for the specified gender
+	GetForGender(gender string) (count int)
 	// WaitTillReady waits until the fetcher is ready or until the context is marked as done
 	WaitTillReady(ctx context.Context) error
 }
 
 var _ PopulationFetcher = &population.Server{}
 
+var _ Reporter = &ProRater{}
+
+func (r *ProRater) GetName() string {
+	return r.Name
+}
+
 func (r *ProRater) Run(ctx context.Context) error {
 	ch := make(chan sciensano.Vaccinations, 1)
 	r.Source.Register(ch)
@@ -53,12 +65,12 @@ func (r *ProRater) Run(ctx context.Context) error {
 }
 
 func (r *ProRater) createReport(vaccinations sciensano.Vaccinations) {
-	t, err := filterVaccinations(vaccinations, r.Mode, r.DoseType)
+	t, err := filterVaccinations(slices.Values(vaccinations), r.Mode, r.DoseType)
 	if err != nil {
 		r.Logger.Error("failed to generate report", "err", err)
 		return
 	}
-	t, err = proRate(t, r.Mode, r.PopStore)
+	t, err = proRate(t, r.Mode, r.PopStore, 1)
 	if err != nil {
 		r.Logger.Error("failed to generate prorated report", "err", err)
 		return
@@ -66,18 +78,18 @@ func (r *ProRater) createReport(vaccinations sciensano.Vaccinations) {
 	r.Store.Put(r.Name, t)
 }
 
-func filterVaccinations(vaccinations sciensano.Vaccinations, mode sciensano.SummaryColumn, doseType sciensano.DoseType) (*tabulator.Tabulator, error) {
+func filterVaccinations(vaccinations iter.Seq[sciensano.Vaccination], mode sciensano.SummaryColumn, doseType sciensano.DoseType) (*tabulator.Tabulator, error) {
 	t := tabulator.New()
 	columnNames := set.New[string]()
 
 	// Filtering and then calling summary has a major performance impact.
 	// This is basically the same code as Summary, but filters on the fly to avoid copying the large vaccinations slice.
-	for i := range vaccinations {
-		if vaccinations[i].Dose != doseType && !(doseType == sciensano.Full && vaccinations[i].Dose == sciensano.SingleDose) {
+	for vaccination := range vaccinations {
+		if vaccination.Dose != doseType && !(doseType == sciensano.Full && vaccination.Dose == sciensano.SingleDose) {
 			continue
 		}
 
-		columnName, err := vaccinations[i].GetSummaryColumnName(mode)
+		columnName, err := vaccination.GetSummaryColumnName(mode)
 		if err != nil {
 			return nil, err
 		}
@@ -90,12 +102,13 @@ func filterVaccinations(vaccinations sciensano.Vaccinations, mode sciensano.Summ
 			columnNames.Add(columnName)
 		}
 
-		t.Add(vaccinations[i].TimeStamp.Time, columnName, float64(vaccinations[i].Count))
+		t.Add(vaccination.TimeStamp.Time, columnName, float64(vaccination.Count))
 	}
 	return t, nil
 }
 
-func proRate(summary *tabulator.Tabulator, mode sciensano.SummaryColumn, popStore PopulationFetcher) (*tabulator.Tabulator, error) {
+// proRate divides each value by the population of its column. per scales the result, e.g. 100000 returns the rate per 100k people.
+func proRate(summary *tabulator.Tabulator, mode sciensano.SummaryColumn, popStore PopulationFetcher, per float64) (*tabulator.Tabulator, error) {
 	figures, err := getPopulationForGroup(mode, summary.GetColumns(), popStore)
 	if err != nil {
 		return nil, err
@@ -108,7 +121,7 @@ func proRate(summary *tabulator.Tabulator, mode sciensano.SummaryColumn, popStor
 			var newValue float64
 			figure, ok := figures[column]
 			if ok && figure != 0 {
-				newValue = oldValue / float64(figure)
+				newValue = per * oldValue / float64(figure)
 			}
 			rated.Set(timestamps[index], column, newValue)
 		}
@@ -133,12 +146,16 @@ func getPopulationForGroup(mode sciensano.SummaryColumn, columns []string, popSt
 		switch mode {
 		case sciensano.ByRegion:
 			pop = popStore.GetForRegion(column)
+		case sciensano.ByProvince:
+			pop = popStore.GetForProvince(column)
 		case sciensano.ByAgeGroup:
 			b, err := bracket.FromString(column)
 			if err != nil {
 				return nil, fmt.Errorf("invalid age bracket: '%s' : %w", column, err)
 			}
 			pop = popStore.GetForAgeBracket(b)
+		case sciensano.ByGender:
+			pop = popStore.GetForGender(column)
 		default:
 		}
 		figures[column] = pop
@@ -25,13 +25,15 @@ func TestProRater_Prorate(t *testing.T) {
 	f.EXPECT().GetForAgeBracket(bracket.Bracket{Low: 20, High: 29}).Return(10)
 	f.EXPECT().GetForAgeBracket(bracket.Bracket{Low: 30, High: 39}).Return(5)
 	f.EXPECT().GetForAgeBracket(bracket.Bracket{Low: 40, High: 49}).Return(1)
+	f.EXPECT().GetForGender("M").Return(11)
+	f.EXPECT().GetForGender("F").Return(5)
 	f.EXPECT().WaitTillReady(mock.AnythingOfType("*context.timerCtx")).Return(nil)
 
 	ts := time.Date(2023, time.August, 7, 0, 0, 0, 0, time.UTC)
 	vaccinations := sciensano.Vaccinations{
-		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Flanders", AgeGroup: "20-29", Dose: sciensano.Partial, Count: 100},
-		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Wallonia", AgeGroup: "30-39", Dose: sciensano.Partial, Count: 50},
-		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Brussels", AgeGroup: "40-49", Dose: sciensano.Partial, Count: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Flanders", AgeGroup: "20-29", Gender: "M", Dose: sciensano.Partial, Count: 100},
+		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Wallonia", AgeGroup: "30-39", Gender: "F", Dose: sciensano.Partial, Count: 50},
+		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Brussels", AgeGroup: "40-49", Gender: "M", Dose: sciensano.Partial, Count: 10},
 		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Flanders", Dose: sciensano.Full, Count: 10},
 		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Wallonia", Dose: sciensano.SingleDose, Count: 5},
 		{TimeStamp: sciensano.TimeStamp{Time: ts}, Region: "Brussels", Dose: sciensano.Full, Count: 1},
@@ -71,6 +73,13 @@ func TestProRater_Prorate(t *testing.T) {
 			wantColumns: []string{"20-29", "30-39", "40-49"},
 			wantValues:  []float64{10},
 		},
+		{
+			name:        "Partial-ByGender",
+			mode:        sciensano.ByGender,
+			doseType:    sciensano.Partial,
+			wantColumns: []string{"F", "M"},
+			wantValues:  []float64{10},
+		},
 		{
 			name:        "Full-ByAgeGroup",
 			mode:        sciensano.ByAgeGroup,
@@ -138,6 +147,14 @@ func (f fakePopStore) GetForRegion(_ string) (count int) {
 	return 1
 }
 
+func (f fakePopStore) GetForProvince(_ string) (count int) {
+	return 1
+}
+
+func (f fakePopStore) GetForGender(_ string) (count int) {
+	return 1
+}
+
 func (f fakePopStore) WaitTillReady(_ context.Context) error {
 	return nil
 }
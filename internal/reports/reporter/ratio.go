@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"context"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+	"time"
+)
+
+// Ratio stores the ratio of two datasources, e.g. the number of deaths per case. Since the numerator typically lags
+// the denominator, the numerator over a window of Window days is divided by the denominator over the same window, Lag days earlier.
+//
+// Ratio uses a Combiner, so the report is recomputed whenever either datasource publishes new data.
+type Ratio[N, D any] struct {
+	Name                 string
+	Numerator            Publisher[N]
+	Denominator          Publisher[D]
+	SummarizeNumerator   func(N, sciensano.SummaryColumn) (*tabulator.Tabulator, error)
+	SummarizeDenominator func(D, sciensano.SummaryColumn) (*tabulator.Tabulator, error)
+	Mode                 sciensano.SummaryColumn
+	Lag                  int
+	Window               int
+	Store                *store.Store
+	Logger               *slog.Logger
+}
+
+var _ Reporter = &Ratio[sciensano.Mortalities, sciensano.Cases]{}
+
+func (r *Ratio[N, D]) GetName() string {
+	return r.Name
+}
+
+func (r *Ratio[N, D]) Run(ctx context.Context) error {
+	numerator := &Input[N]{Source: r.Numerator}
+	denominator := &Input[D]{Source: r.Denominator}
+	c := Combiner{
+		Name:    r.Name,
+		Inputs:  []CombinerInput{numerator, denominator},
+		Combine: func() (*tabulator.Tabulator, error) { return r.combine(numerator.Latest(), denominator.Latest()) },
+		Store:   r.Store,
+		Logger:  r.Logger,
+	}
+	return c.Run(ctx)
+}
+
+func (r *Ratio[N, D]) combine(numerator N, denominator D) (*tabulator.Tabulator, error) {
+	n, err := r.SummarizeNumerator(numerator, r.Mode)
+	if err != nil {
+		return nil, err
+	}
+	d, err := r.SummarizeDenominator(denominator, r.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return lagRatio(n, d, r.Lag, r.Window), nil
+}
+
+// lagRatio divides the numerator over a window of days days by the denominator over the same window, lag days earlier.
+// Columns are taken from the numerator. If the denominator is zero, the ratio is zero.
+func lagRatio(numerator, denominator *tabulator.Tabulator, lag, days int) *tabulator.Tabulator {
+	if days < 1 {
+		days = 1
+	}
+	timestamps := numerator.GetTimestamps()
+	columns := numerator.GetColumns()
+	ratios := tabulator.New(columns...)
+	for _, column := range columns {
+		n := valuesByDay(numerator, column)
+		d := valuesByDay(denominator, column)
+		for _, timestamp := range timestamps {
+			var nTotal, dTotal float64
+			for day := range days {
+				nTotal += n[timestamp.AddDate(0, 0, -day)]
+				dTotal += d[timestamp.AddDate(0, 0, -day-lag)]
+			}
+			var ratio float64
+			if dTotal > 0 {
+				ratio = nTotal / dTotal
+			}
+			ratios.Set(timestamp, column, ratio)
+		}
+	}
+	return ratios
+}
+
+func valuesByDay(t *tabulator.Tabulator, column string) map[time.Time]float64 {
+	values, ok := t.GetValues(column)
+	if !ok {
+		return nil
+	}
+	byDay := make(map[time.Time]float64, len(values))
+	for index, timestamp := range t.GetTimestamps() {
+		byDay[timestamp] = values[index]
+	}
+	return byDay
+}
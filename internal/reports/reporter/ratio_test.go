@@ -0,0 +1,111 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func Test_lagRatio(t *testing.T) {
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	numerator := tabulator.New("A", "B")
+	denominator := tabulator.New("A")
+	for i, value := range []float64{10, 20, 30, 40} {
+		numerator.Set(day.AddDate(0, 0, i), "A", value/10)
+		numerator.Set(day.AddDate(0, 0, i), "B", 1)
+		denominator.Set(day.AddDate(0, 0, i), "A", value)
+	}
+
+	testCases := []struct {
+		name string
+		lag  int
+		days int
+		want []float64
+	}{
+		{name: "daily", lag: 0, days: 1, want: []float64{0.1, 0.1, 0.1, 0.1}},
+		{name: "lagged", lag: 1, days: 1, want: []float64{0, 0.2, 0.15, 4.0 / 30}},
+		{name: "window", lag: 1, days: 2, want: []float64{0, 0.3, 5.0 / 30, 7.0 / 50}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ratios := lagRatio(numerator, denominator, tt.lag, tt.days)
+			assert.Equal(t, []string{"A", "B"}, ratios.GetColumns())
+			values, ok := ratios.GetValues("A")
+			require.True(t, ok)
+			require.Len(t, values, len(tt.want))
+			for i := range tt.want {
+				assert.InDelta(t, tt.want[i], values[i], 1e-9)
+			}
+			values, ok = ratios.GetValues("B")
+			require.True(t, ok)
+			assert.Equal(t, []float64{0, 0, 0, 0}, values)
+		})
+	}
+}
+
+func TestRatio(t *testing.T) {
+	mortalitiesChCh := make(chan chan sciensano.Mortalities)
+	mortalities := mocks.NewPublisher[sciensano.Mortalities](t)
+	mortalities.EXPECT().Register(mock.AnythingOfType("chan sciensano.Mortalities")).Run(func(ch chan sciensano.Mortalities) {
+		mortalitiesChCh <- ch
+	})
+	mortalities.EXPECT().Unregister(mock.AnythingOfType("chan sciensano.Mortalities"))
+
+	casesChCh := make(chan chan sciensano.Cases)
+	cases := mocks.NewPublisher[sciensano.Cases](t)
+	cases.EXPECT().Register(mock.AnythingOfType("chan sciensano.Cases")).Run(func(ch chan sciensano.Cases) {
+		casesChCh <- ch
+	})
+	cases.EXPECT().Unregister(mock.AnythingOfType("chan sciensano.Cases"))
+
+	r := Ratio[sciensano.Mortalities, sciensano.Cases]{
+		Name:                 "case-fatality-ratio-ByAgeGroup",
+		Numerator:            mortalities,
+		Denominator:          cases,
+		SummarizeNumerator:   sciensano.Mortalities.Summarize,
+		SummarizeDenominator: sciensano.Cases.SummarizeForMortalities,
+		Mode:                 sciensano.ByAgeGroup,
+		Lag:                  14,
+		Window:               7,
+		Store:                &store.Store{Logger: slog.Default().With("component", "store")},
+		Logger:               slog.Default().With("reporter", "case-fatality-ratio-ByAgeGroup"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan error)
+	go func() {
+		ch <- r.Run(ctx)
+	}()
+
+	mortalitiesCh := <-mortalitiesChCh
+	casesCh := <-casesChCh
+
+	// no report until both datasources have published
+	mortalitiesCh <- testutil.Mortalities()
+	_, err := r.Store.Get("case-fatality-ratio-ByAgeGroup")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	casesCh <- testutil.Cases()
+	assert.Eventually(t, func() bool {
+		_, err := r.Store.Get("case-fatality-ratio-ByAgeGroup")
+		return !errors.Is(err, store.ErrNotFound)
+	}, time.Minute, time.Second)
+
+	report, err := r.Store.Get("case-fatality-ratio-ByAgeGroup")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(unknown)", "0-24", "25-44", "45-64", "65-74", "75-84", "85+"}, report.GetColumns())
+
+	cancel()
+	assert.ErrorIs(t, <-ch, context.Canceled)
+}
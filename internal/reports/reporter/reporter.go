@@ -4,3 +4,8 @@ type Publisher[T any] interface {
 	Register(chan T)
 	Unregister(chan T)
 }
+
+// Reporter is implemented by tasks that store a report. GetName returns the key under which the report is stored.
+type Reporter interface {
+	GetName() string
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"log/slog"
+	"time"
+)
+
+// RtParameters configure the estimation of the effective reproduction number
+type RtParameters struct {
+	// Window is the number of days over which Rt is assumed to be constant
+	Window int
+	// SerialIntervalMean is the mean of the (gamma-distributed) serial interval, in days
+	SerialIntervalMean float64
+	// SerialIntervalSD is the standard deviation of the serial interval, in days
+	SerialIntervalSD float64
+}
+
+// Validate returns an error if the parameters are invalid
+func (p RtParameters) Validate() error {
+	if p.Window <= 0 {
+		return fmt.Errorf("rt window must be positive: %d", p.Window)
+	}
+	if p.SerialIntervalMean <= 0 {
+		return fmt.Errorf("serial interval mean must be positive: %g", p.SerialIntervalMean)
+	}
+	if p.SerialIntervalSD <= 0 {
+		return fmt.Errorf("serial interval standard deviation must be positive: %g", p.SerialIntervalSD)
+	}
+	return nil
+}
+
+// DefaultRtParameters uses a weekly window and the serial interval reported by Nishiura et al. (2020)
+var DefaultRtParameters = RtParameters{
+	Window:             7,
+	SerialIntervalMean: 4.7,
+	SerialIntervalSD:   2.9,
+}
+
+const (
+	// prior for Rt: gamma distribution with mean 5 and standard deviation 5, as proposed by Cori et al.
+	rtPriorShape = 1.0
+	rtPriorScale = 5.0
+	// credible interval bounds
+	rtLowQuantile  = 0.025
+	rtHighQuantile = 0.975
+)
+
+// Rt estimates the effective reproduction number from the number of cases, using the method of Cori et al. (2013).
+// For each column of the cases summary, it stores the mean of the posterior distribution of Rt, as well as the bounds of
+// its 95% credible interval, as extra columns "<column> (low)" and "<column> (high)". A bound that can't be calculated
+// with sufficient precision is stored as NaN.
+type Rt struct {
+	Name       string
+	Source     Publisher[sciensano.Cases]
+	Mode       sciensano.SummaryColumn
+	Parameters RtParameters
+	Store      *store.Store
+	Logger     *slog.Logger
+}
+
+var _ Reporter = &Rt{}
+
+func (r *Rt) GetName() string {
+	return r.Name
+}
+
+func (r *Rt) Run(ctx context.Context) error {
+	ch := make(chan sciensano.Cases)
+	r.Source.Register(ch)
+	defer func() {
+		r.Source.Unregister(ch)
+		close(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-ch:
+			r.createReport(data)
+		}
+	}
+}
+
+func (r *Rt) createReport(cases sciensano.Cases) {
+	summary, err := cases.Summarize(r.Mode)
+	if err != nil {
+		r.Logger.Error("failed to generate report", "err", err)
+		return
+	}
+	r.Store.Put(r.Name, estimateRt(summary, r.Parameters))
+}
+
+func estimateRt(summary *tabulator.Tabulator, parameters RtParameters) *tabulator.Tabulator {
+	w := discreteSerialInterval(parameters.SerialIntervalMean, parameters.SerialIntervalSD)
+	days, incidences := dailyIncidence(summary)
+
+	var columns []string
+	for _, column := range summary.GetColumns() {
+		if column == "(unknown)" {
+			continue
+		}
+		columns = append(columns, column, column+" (low)", column+" (high)")
+	}
+	t := tabulator.New(columns...)
+
+	for _, column := range summary.GetColumns() {
+		if column == "(unknown)" {
+			continue
+		}
+		incidence := incidences[column]
+		infectiousness := totalInfectiousness(incidence, w)
+		for day := parameters.Window; day < len(incidence); day++ {
+			var sumIncidence, sumInfectiousness float64
+			for s := day - parameters.Window + 1; s <= day; s++ {
+				sumIncidence += incidence[s]
+				sumInfectiousness += infectiousness[s]
+			}
+			if sumInfectiousness == 0 {
+				continue
+			}
+			shape := rtPriorShape + sumIncidence
+			scale := 1 / (1/rtPriorScale + sumInfectiousness)
+			t.Set(days[day], column, shape*scale)
+			t.Set(days[day], column+" (low)", gammaQuantile(rtLowQuantile, shape, scale))
+			t.Set(days[day], column+" (high)", gammaQuantile(rtHighQuantile, shape, scale))
+		}
+	}
+	return t
+}
+
+// dailyIncidence returns the values of the summary for every day between the first and last timestamp.
+// Days that aren't in the summary have no cases.
+func dailyIncidence(summary *tabulator.Tabulator) ([]time.Time, map[string][]float64) {
+	timestamps := summary.GetTimestamps()
+	if len(timestamps) == 0 {
+		return nil, nil
+	}
+	var days []time.Time
+	for day := timestamps[0]; !day.After(timestamps[len(timestamps)-1]); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	incidences := make(map[string][]float64)
+	for _, column := range summary.GetColumns() {
+		values, _ := summary.GetValues(column)
+		incidence := make([]float64, len(days))
+		day := 0
+		for index, timestamp := range timestamps {
+			for days[day].Before(timestamp) {
+				day++
+			}
+			incidence[day] = values[index]
+		}
+		incidences[column] = incidence
+	}
+	return days, incidences
+}
+
+// totalInfectiousness returns, for each day, the sum of the incidence of the previous days, weighted by the serial interval distribution w.
+func totalInfectiousness(incidence []float64, w []float64) []float64 {
+	infectiousness := make([]float64, len(incidence))
+	for day := range incidence {
+		for k := 1; k < len(w) && k <= day; k++ {
+			infectiousness[day] += incidence[day-k] * w[k]
+		}
+	}
+	return infectiousness
+}
+
+// discreteSerialInterval discretizes a gamma-distributed serial interval with the specified mean and standard deviation.
+// w[k] is the probability that the serial interval is k days. w[0] is always zero.
+func discreteSerialInterval(mean, sd float64) []float64 {
+	const maxDays = 60
+	shape, scale := (mean*mean)/(sd*sd), (sd*sd)/mean
+
+	w := []float64{0}
+	var total float64
+	for k := 1; k <= maxDays; k++ {
+		p := gammaCDF(float64(k), shape, scale) - gammaCDF(float64(k-1), shape, scale)
+		w = append(w, p)
+		total += p
+		if gammaCDF(float64(k), shape, scale) > 0.999 {
+			break
+		}
+	}
+	for k := range w {
+		w[k] /= total
+	}
+	return w
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_discreteSerialInterval(t *testing.T) {
+	w := discreteSerialInterval(4.7, 2.9)
+	assert.Zero(t, w[0])
+	var total, mean float64
+	for k, p := range w {
+		total += p
+		mean += float64(k) * p
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	assert.InDelta(t, 4.7, mean, 0.5)
+}
+
+func Test_estimateRt(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	summary := tabulator.New("Constant", "Growing")
+	for day := range 60 {
+		summary.Set(start.AddDate(0, 0, day), "Constant", 1000)
+		summary.Set(start.AddDate(0, 0, day), "Growing", 100*math.Exp(0.1*float64(day)))
+	}
+
+	rt := estimateRt(summary, DefaultRtParameters)
+	assert.Equal(t, []string{"Constant", "Constant (high)", "Constant (low)", "Growing", "Growing (high)", "Growing (low)"}, rt.GetColumns())
+
+	// the first days don't have enough history to estimate Rt
+	timestamps := rt.GetTimestamps()
+	require.NotEmpty(t, timestamps)
+	assert.Equal(t, start.AddDate(0, 0, DefaultRtParameters.Window), timestamps[0])
+
+	constant, _ := rt.GetValues("Constant")
+	low, _ := rt.GetValues("Constant (low)")
+	high, _ := rt.GetValues("Constant (high)")
+	last := len(constant) - 1
+	assert.InDelta(t, 1, constant[last], 0.01)
+	assert.Less(t, low[last], constant[last])
+	assert.Greater(t, high[last], constant[last])
+
+	// with exponential growth, Rt = 1 / M(-r), where M is the moment generating function of the serial interval
+	w := discreteSerialInterval(DefaultRtParameters.SerialIntervalMean, DefaultRtParameters.SerialIntervalSD)
+	var m float64
+	for k, p := range w {
+		m += p * math.Exp(-0.1*float64(k))
+	}
+	growing, _ := rt.GetValues("Growing")
+	assert.InDelta(t, 1/m, growing[last], 0.01)
+}
+
+func TestRt_createReport(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var cases sciensano.Cases
+	for day := range 30 {
+		// a day without any cases doesn't show up in the data
+		if day == 10 {
+			continue
+		}
+		cases = append(cases,
+			sciensano.Case{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Region: "Flanders", Cases: 100},
+			sciensano.Case{TimeStamp: sciensano.TimeStamp{Time: start.AddDate(0, 0, day)}, Cases: 1},
+		)
+	}
+
+	r := Rt{
+		Name:       "rt-ByRegion",
+		Mode:       sciensano.ByRegion,
+		Parameters: DefaultRtParameters,
+		Store:      &store.Store{Logger: slog.Default()},
+		Logger:     slog.Default(),
+	}
+	r.createReport(cases)
+
+	report, err := r.Store.Get("rt-ByRegion")
+	require.NoError(t, err)
+	// cases without a region aren't estimated
+	assert.Equal(t, []string{"Flanders", "Flanders (high)", "Flanders (low)"}, report.GetColumns())
+	assert.Equal(t, 30-DefaultRtParameters.Window, report.Size())
+}
@@ -20,6 +20,12 @@ type summarizer interface {
 	Summarize(column sciensano.SummaryColumn) (*tabulator.Tabulator, error)
 }
 
+var _ Reporter = &Summary[sciensano.Cases]{}
+
+func (s *Summary[T]) GetName() string {
+	return s.Name
+}
+
 func (s *Summary[T]) Run(ctx context.Context) error {
 	ch := make(chan T)
 	s.Source.Register(ch)
@@ -1,7 +1,10 @@
 package reports
 
 import (
+	"fmt"
+	"github.com/clambin/go-common/tabulator"
 	"github.com/clambin/go-common/taskmanager"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/reports/datasource"
 	"github.com/clambin/sciensano/v2/internal/reports/reporter"
 	"github.com/clambin/sciensano/v2/internal/reports/store"
@@ -9,81 +12,184 @@ import (
 	"log/slog"
 )
 
-type datasourceType int
+// Options configure the reporters created by NewSciensanoReporters
+type Options struct {
+	// PositivityWindow is the number of days over which the test positivity rate is calculated
+	PositivityWindow int
+	// Rt configures the estimation of the effective reproduction number
+	Rt reporter.RtParameters
+	// GrowthWindow is the number of days over which the growth rate and doubling time are calculated
+	GrowthWindow int
+	// FatalityLag is the number of days between a case and a resulting death, used by the case fatality ratio
+	FatalityLag int
+	// HospitalisationLag is the number of days between a case and a resulting admission, used by the hospitalisation ratio
+	HospitalisationLag int
+	// RatioWindow is the number of days over which the case fatality and hospitalisation ratios are calculated
+	RatioWindow int
+}
 
-const (
-	casesDatasource datasourceType = iota
-	hospitalisationsDatasource
-	mortalitiesDatasource
-	testResultsDatasource
-	vaccinationsDatasource
-)
+var DefaultOptions = Options{
+	PositivityWindow:   7,
+	Rt:                 reporter.DefaultRtParameters,
+	GrowthWindow:       7,
+	FatalityLag:        14,
+	HospitalisationLag: 7,
+	RatioWindow:        7,
+}
 
-func NewSciensanoReporters(datasources *datasource.SciensanoSources, store *store.Store, popStore reporter.PopulationFetcher, logger *slog.Logger) []taskmanager.Task {
-	summarizers := []struct {
-		dsType   datasourceType
-		basename string
-		modes    []sciensano.SummaryColumn
-	}{
-		{dsType: casesDatasource, basename: "cases", modes: []sciensano.SummaryColumn{sciensano.Total, sciensano.ByProvince, sciensano.ByRegion, sciensano.ByAgeGroup}},
-		{dsType: hospitalisationsDatasource, basename: "hospitalisations", modes: []sciensano.SummaryColumn{sciensano.Total, sciensano.ByProvince, sciensano.ByRegion, sciensano.ByCategory}},
-		{dsType: mortalitiesDatasource, basename: "mortalities", modes: []sciensano.SummaryColumn{sciensano.Total, sciensano.ByRegion, sciensano.ByAgeGroup}},
-		{dsType: testResultsDatasource, basename: "tests", modes: []sciensano.SummaryColumn{sciensano.Total, sciensano.ByCategory}},
-		{dsType: vaccinationsDatasource, basename: "vaccinations", modes: []sciensano.SummaryColumn{sciensano.Total, sciensano.ByRegion, sciensano.ByAgeGroup, sciensano.ByManufacturer, sciensano.ByVaccinationType}},
+// Validate returns an error if the options are invalid
+func (o Options) Validate() error {
+	if o.PositivityWindow <= 0 {
+		return fmt.Errorf("positivity window must be positive: %d", o.PositivityWindow)
+	}
+	if err := o.Rt.Validate(); err != nil {
+		return err
+	}
+	if o.GrowthWindow < reporter.MinGrowthWindow {
+		return fmt.Errorf("growth window must be at least %d days: %d", reporter.MinGrowthWindow, o.GrowthWindow)
+	}
+	if o.FatalityLag < 0 {
+		return fmt.Errorf("fatality lag can't be negative: %d", o.FatalityLag)
+	}
+	if o.HospitalisationLag < 0 {
+		return fmt.Errorf("hospitalisation lag can't be negative: %d", o.HospitalisationLag)
 	}
+	if o.RatioWindow <= 0 {
+		return fmt.Errorf("ratio window must be positive: %d", o.RatioWindow)
+	}
+	return nil
+}
 
+// NewSciensanoReporters creates the reporters for the reports in the configuration. The configuration must be valid.
+func NewSciensanoReporters(datasources *datasource.SciensanoSources, store *store.Store, popStore reporter.PopulationFetcher, cfg config.Config, options Options, logger *slog.Logger) []taskmanager.Task {
 	var reporters []taskmanager.Task
-	for _, option := range summarizers {
-		for _, mode := range option.modes {
-			fullName := option.basename + "-" + mode.String()
-			l := logger.With(slog.String("reporter", fullName))
-
-			var task taskmanager.Task
-			switch option.dsType {
-			case casesDatasource:
-				task = &reporter.Summary[sciensano.Cases]{Name: fullName, Source: &datasources.Cases, Mode: mode, Store: store, Logger: l}
-			case hospitalisationsDatasource:
-				task = &reporter.Summary[sciensano.Hospitalisations]{Name: fullName, Source: &datasources.Hospitalisations, Mode: mode, Store: store, Logger: l}
-			case mortalitiesDatasource:
-				task = &reporter.Summary[sciensano.Mortalities]{Name: fullName, Source: &datasources.Mortalities, Mode: mode, Store: store, Logger: l}
-			case testResultsDatasource:
-				task = &reporter.Summary[sciensano.TestResults]{Name: fullName, Source: &datasources.TestResults, Mode: mode, Store: store, Logger: l}
-			case vaccinationsDatasource:
-				task = &reporter.Summary[sciensano.Vaccinations]{Name: fullName, Source: &datasources.Vaccinations, Mode: mode, Store: store, Logger: l}
+	for _, report := range cfg.Reports {
+		for _, mode := range report.GetModes() {
+			switch report.GetType() {
+			case config.SummaryReport:
+				for _, transform := range report.GetTransforms() {
+					reporters = append(reporters, newSummaryReporter(report, mode, transform, datasources, store, popStore, options, logger))
+				}
+			case config.VaccinationRateReport:
+				for _, doseType := range report.GetDoseTypes() {
+					fullName := report.DoseTypeKey(doseType, mode)
+					reporters = append(reporters, &reporter.ProRater{
+						Name:     fullName,
+						Source:   &datasources.Vaccinations,
+						PopStore: popStore,
+						Mode:     mode,
+						DoseType: doseType,
+						Store:    store,
+						Logger:   logger.With("reporter", fullName),
+					})
+				}
 			default:
-				panic("invalid mode")
+				reporters = append(reporters, newDerivedReporter(report, mode, datasources, store, options, logger))
 			}
-			reporters = append(reporters, task)
 		}
 	}
+	return reporters
+}
+
+// newSummaryReporter creates the reporter for a transform of a summary of the report's datasource.
+func newSummaryReporter(report config.Report, mode sciensano.SummaryColumn, transform config.Transform, datasources *datasource.SciensanoSources, store *store.Store, popStore reporter.PopulationFetcher, options Options, logger *slog.Logger) taskmanager.Task {
+	fullName := report.Key(mode, transform)
+	l := logger.With("reporter", fullName)
 
-	raters := []struct {
-		dsType   datasourceType
-		basename string
-		modes    []sciensano.SummaryColumn
-		doseType sciensano.DoseType
-	}{
-		{dsType: vaccinationsDatasource, basename: "vaccination-rate", modes: []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup}, doseType: sciensano.Partial},
-		{dsType: vaccinationsDatasource, basename: "vaccination-rate", modes: []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup}, doseType: sciensano.Full},
+	if transform == config.PerHospital {
+		return &reporter.PerHospital{Name: fullName, Source: &datasources.Hospitalisations, Mode: mode, Store: store, Logger: l}
 	}
 
-	for _, rater := range raters {
-		for _, mode := range rater.modes {
-			fullName := rater.basename + "-" + rater.doseType.String() + "-" + mode.String()
-			l := logger.With("reporter", fullName)
+	switch sciensano.EndpointNames[report.Datasource] {
+	case sciensano.CasesEndpoint:
+		return newTransformReporter(fullName, &datasources.Cases, sciensano.Cases.Summarize, mode, transform, store, popStore, options, l)
+	case sciensano.HospitalisationsEndpoint:
+		// the incidence and growth of hospitalisations are those of new admissions, rather than of the number of patients in hospital
+		return newTransformReporter(fullName, &datasources.Hospitalisations, sciensano.Hospitalisations.SummarizeAdmissions, mode, transform, store, popStore, options, l)
+	case sciensano.MortalitiesEndpoint:
+		return newTransformReporter(fullName, &datasources.Mortalities, sciensano.Mortalities.Summarize, mode, transform, store, popStore, options, l)
+	case sciensano.TestResultsEndpoint:
+		return newTransformReporter(fullName, &datasources.TestResults, sciensano.TestResults.Summarize, mode, transform, store, popStore, options, l)
+	case sciensano.VaccinationsEndpoint:
+		return newTransformReporter(fullName, &datasources.Vaccinations, sciensano.Vaccinations.Summarize, mode, transform, store, popStore, options, l)
+	case sciensano.MunicipalityCasesEndpoint:
+		return newTransformReporter(fullName, &datasources.MunicipalityCases, sciensano.MunicipalityCases.Summarize, mode, transform, store, popStore, options, l)
+	default:
+		panic(fmt.Sprintf("invalid datasource: %s", report.Datasource))
+	}
+}
 
-			reporters = append(reporters, &reporter.ProRater{
-				Name:     fullName,
-				Source:   &datasources.Vaccinations,
-				PopStore: popStore,
-				Mode:     mode,
-				DoseType: rater.doseType,
-				Store:    store,
-				Logger:   l,
-			})
-		}
+type summarizer interface {
+	Summarize(column sciensano.SummaryColumn) (*tabulator.Tabulator, error)
+}
 
+// newTransformReporter creates the reporter for a transform of a summary. The incidence rates, growth and doubling time
+// are calculated from the new daily figures, as summarized by summarizeDaily.
+func newTransformReporter[T summarizer](name string, source reporter.Publisher[T], summarizeDaily func(T, sciensano.SummaryColumn) (*tabulator.Tabulator, error), mode sciensano.SummaryColumn, transform config.Transform, store *store.Store, popStore reporter.PopulationFetcher, options Options, logger *slog.Logger) taskmanager.Task {
+	switch transform {
+	case config.Absolute:
+		return &reporter.Summary[T]{Name: name, Source: source, Mode: mode, Store: store, Logger: logger}
+	case config.Per100k:
+		return &reporter.Incidence[T]{Name: name, Source: source, Summarize: summarizeDaily, Mode: mode, PopStore: popStore, Store: store, Logger: logger}
+	case config.Per100k14d:
+		return &reporter.Incidence[T]{Name: name, Source: source, Summarize: summarizeDaily, Mode: mode, Days: 14, PopStore: popStore, Store: store, Logger: logger}
+	case config.Growth:
+		return &reporter.Growth[T]{Name: name, Source: source, Summarize: summarizeDaily, Mode: mode, Metric: reporter.WeeklyGrowth, Window: options.GrowthWindow, Store: store, Logger: logger}
+	case config.DoublingTime:
+		return &reporter.Growth[T]{Name: name, Source: source, Summarize: summarizeDaily, Mode: mode, Metric: reporter.DoublingTime, Window: options.GrowthWindow, Store: store, Logger: logger}
+	default:
+		panic(fmt.Sprintf("invalid transform: %s", transform))
 	}
+}
 
-	return reporters
+// newDerivedReporter creates the reporter for a report that is derived from one or more datasources.
+func newDerivedReporter(report config.Report, mode sciensano.SummaryColumn, datasources *datasource.SciensanoSources, store *store.Store, options Options, logger *slog.Logger) taskmanager.Task {
+	fullName := report.Key(mode, config.Absolute)
+	l := logger.With("reporter", fullName)
+
+	switch report.GetType() {
+	case config.PositivityRateReport:
+		return &reporter.PositivityRate{Name: fullName, Source: &datasources.TestResults, Mode: mode, Days: options.PositivityWindow, Store: store, Logger: l}
+	case config.RtReport:
+		return &reporter.Rt{Name: fullName, Source: &datasources.Cases, Mode: mode, Parameters: options.Rt, Store: store, Logger: l}
+	case config.CaseFatalityRatioReport:
+		return &reporter.Ratio[sciensano.Mortalities, sciensano.Cases]{
+			Name:                 fullName,
+			Numerator:            &datasources.Mortalities,
+			Denominator:          &datasources.Cases,
+			SummarizeNumerator:   sciensano.Mortalities.Summarize,
+			SummarizeDenominator: sciensano.Cases.SummarizeForMortalities,
+			Mode:                 mode,
+			Lag:                  options.FatalityLag,
+			Window:               options.RatioWindow,
+			Store:                store,
+			Logger:               l,
+		}
+	case config.HospitalisationRatioReport:
+		return &reporter.Ratio[sciensano.Hospitalisations, sciensano.Cases]{
+			Name:                 fullName,
+			Numerator:            &datasources.Hospitalisations,
+			Denominator:          &datasources.Cases,
+			SummarizeNumerator:   sciensano.Hospitalisations.SummarizeAdmissions,
+			SummarizeDenominator: sciensano.Cases.Summarize,
+			Mode:                 mode,
+			Lag:                  options.HospitalisationLag,
+			Window:               options.RatioWindow,
+			Store:                store,
+			Logger:               l,
+		}
+	default:
+		panic(fmt.Sprintf("invalid report type: %s", report.GetType()))
+	}
+}
+
+// ReportNames returns the keys under which the reporters store their reports.
+func ReportNames(reporters []taskmanager.Task) []string {
+	names := make([]string, 0, len(reporters))
+	for _, task := range reporters {
+		if r, ok := task.(reporter.Reporter); ok {
+			names = append(names, r.GetName())
+		}
+	}
+	return names
 }
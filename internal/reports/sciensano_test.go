@@ -3,17 +3,22 @@ package reports_test
 import (
 	"context"
 	"github.com/clambin/go-common/taskmanager"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/reports"
 	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/reports/reporter"
 	"github.com/clambin/sciensano/v2/internal/reports/reporter/mocks"
 	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"log/slog"
 	"net/http"
 	"os"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,7 +28,7 @@ func TestSciensanoReporters(t *testing.T) {
 
 	server := testutil.NewTestServer()
 	defer server.Close()
-	datasources := datasource.NewSciensanoDatastore(server.URL, 15*time.Second, http.DefaultClient, logger)
+	datasources := datasource.NewSciensanoDatastore(server.URL, nil, 15*time.Second, http.DefaultClient, logger)
 	mgr := taskmanager.New(datasources)
 
 	s := store.Store{Logger: logger.With("component", "store")}
@@ -31,9 +36,11 @@ func TestSciensanoReporters(t *testing.T) {
 	popStore := mocks.NewPopulationFetcher(t)
 	popStore.EXPECT().GetForRegion(mock.AnythingOfType("string")).Return(1)
 	popStore.EXPECT().GetForAgeBracket(mock.AnythingOfType("bracket.Bracket")).Return(1)
+	popStore.EXPECT().GetForGender(mock.AnythingOfType("string")).Return(1)
+	popStore.EXPECT().GetForProvince(mock.AnythingOfType("string")).Return(1)
 	popStore.EXPECT().WaitTillReady(mock.AnythingOfType("*context.timerCtx")).Return(nil)
 
-	reporters := reports.NewSciensanoReporters(datasources, &s, popStore, logger)
+	reporters := reports.NewSciensanoReporters(datasources, &s, popStore, config.Default(), reports.DefaultOptions, logger)
 	_ = mgr.Add(reporters...)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -41,19 +48,96 @@ func TestSciensanoReporters(t *testing.T) {
 	ch := make(chan error)
 	go func() { ch <- mgr.Run(ctx) }()
 
+	var want []string
+	for basename, modes := range map[string][]string{
+		"cases":            {"ByAgeGroup", "ByGender", "ByProvince", "ByRegion", "Total"},
+		"hospitalisations": {"ByProvince", "ByRegion", "Total"},
+		"mortalities":      {"ByAgeGroup", "ByRegion", "Total"},
+	} {
+		for _, mode := range modes {
+			want = append(want, basename+"-"+mode, basename+"-"+mode+"-growth", basename+"-"+mode+"-doubling-time")
+		}
+	}
+	want = append(want,
+		"hospitalisations-ByCategory",
+		"cases-ByAgeGroup-per100k", "cases-ByAgeGroup-per100k-14d",
+		"cases-ByProvince-per100k", "cases-ByProvince-per100k-14d",
+		"cases-ByRegion-per100k", "cases-ByRegion-per100k-14d",
+		"case-fatality-ratio-ByAgeGroup", "case-fatality-ratio-ByRegion", "case-fatality-ratio-Total",
+		"hospitalisation-ratio-ByProvince", "hospitalisation-ratio-ByRegion", "hospitalisation-ratio-Total",
+		"hospitalisations-ByCategory-per-hospital", "hospitalisations-ByProvince-per-hospital", "hospitalisations-ByRegion-per-hospital", "hospitalisations-Total-per-hospital",
+		"hospitalisations-ByProvince-per100k", "hospitalisations-ByProvince-per100k-14d",
+		"hospitalisations-ByRegion-per100k", "hospitalisations-ByRegion-per100k-14d",
+		"mortalities-ByAgeGroup-per100k", "mortalities-ByAgeGroup-per100k-14d",
+		"mortalities-ByRegion-per100k", "mortalities-ByRegion-per100k-14d",
+		"municipality-cases-ByMunicipality", "municipality-cases-ByProvince", "municipality-cases-ByRegion", "municipality-cases-Total",
+		"positivity-rate-ByProvince", "positivity-rate-ByRegion", "positivity-rate-Total",
+		"rt-ByRegion", "rt-Total",
+		"tests-ByCategory", "tests-Total",
+		"vaccination-rate-Full-ByAgeGroup", "vaccination-rate-Full-ByGender", "vaccination-rate-Full-ByRegion", "vaccination-rate-Partial-ByAgeGroup", "vaccination-rate-Partial-ByGender", "vaccination-rate-Partial-ByRegion",
+		"vaccinations-ByAgeGroup", "vaccinations-ByGender", "vaccinations-ByManufacturer", "vaccinations-ByRegion", "vaccinations-ByVaccinationType", "vaccinations-Total",
+	)
+	slices.Sort(want)
+
+	names := reports.ReportNames(reporters)
+	slices.Sort(names)
+	assert.Equal(t, want, names)
+	keys := config.Default().Keys()
+	slices.Sort(keys)
+	assert.Equal(t, want, keys)
+
 	assert.Eventually(t, func() bool {
 		keys := s.Keys()
 		slices.Sort(keys)
-		return slices.Equal(keys, []string{
-			"cases-ByAgeGroup", "cases-ByProvince", "cases-ByRegion", "cases-Total",
-			"hospitalisations-ByCategory", "hospitalisations-ByProvince", "hospitalisations-ByRegion", "hospitalisations-Total",
-			"mortalities-ByAgeGroup", "mortalities-ByRegion", "mortalities-Total",
-			"tests-ByCategory", "tests-Total",
-			"vaccination-rate-Full-ByAgeGroup", "vaccination-rate-Full-ByRegion", "vaccination-rate-Partial-ByAgeGroup", "vaccination-rate-Partial-ByRegion",
-			"vaccinations-ByAgeGroup", "vaccinations-ByManufacturer", "vaccinations-ByRegion", "vaccinations-ByVaccinationType", "vaccinations-Total",
-		})
+		return slices.Equal(keys, want)
 	}, time.Minute, time.Second)
 
 	cancel()
 	assert.ErrorIs(t, <-ch, context.Canceled)
 }
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, reports.DefaultOptions.Validate())
+
+	for name, invalidate := range map[string]func(*reports.Options){
+		"positivity window": func(o *reports.Options) { o.PositivityWindow = 0 },
+		"rt window":         func(o *reports.Options) { o.Rt.Window = 0 },
+		"rt si mean":        func(o *reports.Options) { o.Rt.SerialIntervalMean = -1 },
+		"rt si sd":          func(o *reports.Options) { o.Rt.SerialIntervalSD = 0 },
+		"growth window":     func(o *reports.Options) { o.GrowthWindow = 1 },
+		"fatality lag":      func(o *reports.Options) { o.FatalityLag = -1 },
+		"hospitalisation":   func(o *reports.Options) { o.HospitalisationLag = -1 },
+		"ratio window":      func(o *reports.Options) { o.RatioWindow = 0 },
+	} {
+		options := reports.DefaultOptions
+		invalidate(&options)
+		assert.Error(t, options.Validate(), name)
+	}
+}
+
+func TestNewSciensanoReporters_HospitalisationIncidence(t *testing.T) {
+	cfg, err := config.Parse(strings.NewReader(`
+reports:
+  - name: hospitalisations
+    datasource: hospitalisations
+    modes: [ByProvince]
+    transforms: [per100k, per100k-14d]
+`))
+	require.NoError(t, err)
+	var datasources datasource.SciensanoSources
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	hospitalisations := sciensano.Hospitalisations{{TimeStamp: sciensano.TimeStamp{Time: day}, Province: "Antwerpen", NewIn: 10, TotalIn: 100}}
+
+	reporters := reports.NewSciensanoReporters(&datasources, &store.Store{Logger: slog.Default()}, nil, cfg, reports.DefaultOptions, slog.Default())
+	require.Len(t, reporters, 2)
+	for _, task := range reporters {
+		// hospitalisation incidence is based on new admissions (NEW_IN), not on bed occupancy (TOTAL_IN)
+		incidence, ok := task.(*reporter.Incidence[sciensano.Hospitalisations])
+		require.True(t, ok)
+		require.NotNil(t, incidence.Summarize)
+		summary, err := incidence.Summarize(hospitalisations, sciensano.ByProvince)
+		require.NoError(t, err)
+		values, _ := summary.GetValues("Antwerpen")
+		assert.Equal(t, []float64{10}, values)
+	}
+}
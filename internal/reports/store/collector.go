@@ -0,0 +1,64 @@
+package store
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"math"
+	"time"
+)
+
+var _ prometheus.Collector = &Collector{}
+
+// Collector exports the most recent value of each column of each report in a Store, so the data can be used in alerts.
+// The value is exported as a gauge, labelled with the report's key, the column and the date of the value.
+//
+// A report has a value for each column on each date. For counts, a column without data on a date is zero, which is a
+// real value. Reports that can't calculate a value for a date (e.g. Rt and ratio reports) store NaN instead: NaN is
+// considered missing, so the collector exports the last value of each column that isn't NaN, with its own date.
+// Columns without any values aren't exported.
+type Collector struct {
+	store  *Store
+	latest *prometheus.Desc
+}
+
+// NewCollector creates a new Collector for the reports in store.
+func NewCollector(store *Store, namespace, subsystem string) *Collector {
+	return &Collector{
+		store: store,
+		latest: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "report_latest_value"),
+			"Most recent value of each column of a report. The date label is the date of the value. Dates without an estimate (NaN) are skipped",
+			[]string{"report", "column", "date"},
+			nil,
+		),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.latest
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, key := range c.store.Keys() {
+		report, err := c.store.Get(key)
+		if err != nil {
+			continue
+		}
+		timestamps := report.GetTimestamps()
+		for _, column := range report.GetColumns() {
+			values, _ := report.GetValues(column)
+			if last := lastValue(values); last >= 0 && last < len(timestamps) {
+				ch <- prometheus.MustNewConstMetric(c.latest, prometheus.GaugeValue, values[last], key, column, timestamps[last].Format(time.DateOnly))
+			}
+		}
+	}
+}
+
+// lastValue returns the index of the last value that isn't missing (NaN), or -1 if all values are missing.
+func lastValue(values []float64) int {
+	for index := len(values) - 1; index >= 0; index-- {
+		if !math.IsNaN(values[index]) {
+			return index
+		}
+	}
+	return -1
+}
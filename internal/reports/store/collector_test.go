@@ -0,0 +1,47 @@
+package store_test
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/reports/store"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	s := store.Store{Logger: slog.Default()}
+	c := store.NewCollector(&s, "sciensano", "")
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader("")))
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cases := tabulator.New("Brussels", "Flanders", "Wallonia")
+	cases.Set(day, "Brussels", 10)
+	cases.Set(day, "Flanders", 20)
+	cases.Set(day, "Wallonia", 5)
+	cases.Set(day.AddDate(0, 0, 1), "Brussels", 15)
+	cases.Set(day.AddDate(0, 0, 1), "Wallonia", 0)
+	s.Put("cases-ByRegion", cases)
+	s.Put("empty", tabulator.New("A"))
+	rt := tabulator.New("Total", "Flanders")
+	rt.Set(day, "Total", 1.1)
+	rt.Set(day, "Flanders", math.NaN())
+	rt.Set(day.AddDate(0, 0, 1), "Total", math.NaN())
+	rt.Set(day.AddDate(0, 0, 1), "Flanders", math.NaN())
+	s.Put("rt-Total", rt)
+
+	// zero is a real value: Flanders had no cases and Wallonia reported zero cases on the latest date.
+	// NaN is missing: the last estimate of Rt is exported with its own date and columns without an estimate aren't exported.
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP sciensano_report_latest_value Most recent value of each column of a report. The date label is the date of the value. Dates without an estimate (NaN) are skipped
+# TYPE sciensano_report_latest_value gauge
+sciensano_report_latest_value{column="Brussels",date="2024-03-02",report="cases-ByRegion"} 15
+sciensano_report_latest_value{column="Flanders",date="2024-03-02",report="cases-ByRegion"} 0
+sciensano_report_latest_value{column="Wallonia",date="2024-03-02",report="cases-ByRegion"} 0
+sciensano_report_latest_value{column="Total",date="2024-03-01",report="rt-Total"} 1.1
+`)))
+}
@@ -49,3 +49,15 @@ func (s *Store) Keys() []string {
 	slices.Sort(keys)
 	return keys
 }
+
+// Retain removes all reports that aren't stored under one of the keys
+func (s *Store) Retain(keys []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key := range s.reports {
+		if !slices.Contains(keys, key) {
+			delete(s.reports, key)
+			s.Logger.Debug("report removed", "name", key)
+		}
+	}
+}
@@ -26,3 +26,18 @@ func TestStore(t *testing.T) {
 
 	assert.Equal(t, []string{"foo"}, s.Keys())
 }
+
+func TestStore_Retain(t *testing.T) {
+	s := store.Store{Logger: slog.Default()}
+	s.Retain([]string{"foo"})
+	assert.Empty(t, s.Keys())
+
+	s.Put("foo", tabulator.New("A"))
+	s.Put("bar", tabulator.New("A"))
+	s.Put("snafu", tabulator.New("A"))
+
+	s.Retain([]string{"foo", "snafu", "missing"})
+	assert.Equal(t, []string{"foo", "snafu"}, s.Keys())
+	_, err := s.Get("bar")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
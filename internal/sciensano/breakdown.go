@@ -0,0 +1,95 @@
+package sciensano
+
+import (
+	"fmt"
+	"github.com/clambin/go-common/set"
+	"github.com/clambin/go-common/tabulator"
+	"slices"
+)
+
+// Breakdown determines how records are summarized: by the Primary summary column and, unless Secondary is Total,
+// by the Secondary summary column within each value of the Primary summary column.
+type Breakdown struct {
+	Primary   SummaryColumn
+	Secondary SummaryColumn
+}
+
+// BreakdownSeparator separates the primary and secondary value in the column names of a two-dimensional breakdown,
+// e.g. "Flanders / 0-9".
+const BreakdownSeparator = " / "
+
+// secondaryBreakdownTags are the summary columns that can be used as a secondary breakdown, and the tag that holds
+// a record's value for each column.
+var secondaryBreakdownTags = map[SummaryColumn]Tag{
+	ByRegion:          RegionTag,
+	ByProvince:        ProvinceTag,
+	ByAgeGroup:        AgeGroupTag,
+	ByManufacturer:    ManufacturerTag,
+	ByVaccinationType: DoseTag,
+}
+
+// SecondaryBreakdownModes returns the summary columns that can be used as a secondary breakdown. A dataset only supports
+// the secondary breakdowns that are also valid summary modes for that dataset.
+func SecondaryBreakdownModes() set.Set[SummaryColumn] {
+	modes := set.Create[SummaryColumn]()
+	for mode := range secondaryBreakdownTags {
+		modes.Add(mode)
+	}
+	return modes
+}
+
+type taggedSummarizer[E Tagged] interface {
+	~[]E
+	Summarize(column SummaryColumn) (*tabulator.Tabulator, error)
+}
+
+// SummarizeBreakdown summarizes the records as determined by the breakdown. For a two-dimensional breakdown, the
+// records are grouped by their secondary value and each group is summarized by the primary summary column.
+// The resulting column names combine the primary and secondary value, separated by BreakdownSeparator.
+func SummarizeBreakdown[S taggedSummarizer[E], E Tagged](records S, breakdown Breakdown) (*tabulator.Tabulator, error) {
+	if breakdown.Secondary == Total {
+		return records.Summarize(breakdown.Primary)
+	}
+	tag, ok := secondaryBreakdownTags[breakdown.Secondary]
+	if !ok || breakdown.Secondary == breakdown.Primary {
+		return nil, fmt.Errorf("invalid secondary breakdown: %s", breakdown.Secondary)
+	}
+
+	groups := make(map[string]S)
+	for _, record := range records {
+		value, ok := record.GetTag(tag)
+		if !ok {
+			return nil, fmt.Errorf("invalid secondary breakdown: %s", breakdown.Secondary)
+		}
+		if value == "" {
+			value = "(unknown)"
+		}
+		groups[value] = append(groups[value], record)
+	}
+
+	summaries := make(map[string]*tabulator.Tabulator, len(groups))
+	var columns []string
+	for value, group := range groups {
+		summary, err := group.Summarize(breakdown.Primary)
+		if err != nil {
+			return nil, err
+		}
+		summaries[value] = summary
+		for _, column := range summary.GetColumns() {
+			columns = append(columns, column+BreakdownSeparator+value)
+		}
+	}
+	slices.Sort(columns)
+
+	t := tabulator.New(columns...)
+	for value, summary := range summaries {
+		timestamps := summary.GetTimestamps()
+		for _, column := range summary.GetColumns() {
+			values, _ := summary.GetValues(column)
+			for index, timestamp := range timestamps {
+				t.Add(timestamp, column+BreakdownSeparator+value, values[index])
+			}
+		}
+	}
+	return t, nil
+}
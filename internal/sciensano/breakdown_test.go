@@ -0,0 +1,92 @@
+package sciensano_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSummarizeBreakdown(t *testing.T) {
+	day1 := sciensano.TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	day2 := sciensano.TimeStamp{Time: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)}
+	mortalities := sciensano.Mortalities{
+		{TimeStamp: day1, Region: "Flanders", AgeGroup: "85+", Deaths: 3},
+		{TimeStamp: day1, Region: "Flanders", AgeGroup: "75-84", Deaths: 2},
+		{TimeStamp: day1, Region: "Brussels", AgeGroup: "85+", Deaths: 1},
+		{TimeStamp: day2, Region: "Flanders", AgeGroup: "85+", Deaths: 4},
+		{TimeStamp: day2, Region: "Brussels", Deaths: 1},
+	}
+
+	testCases := []struct {
+		name      string
+		breakdown sciensano.Breakdown
+		wantErr   assert.ErrorAssertionFunc
+		want      map[string][]float64
+	}{
+		{
+			name:      "one-dimensional",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByRegion},
+			wantErr:   assert.NoError,
+			want: map[string][]float64{
+				"Brussels": {1, 1},
+				"Flanders": {5, 4},
+			},
+		},
+		{
+			name:      "two-dimensional",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByRegion, Secondary: sciensano.ByAgeGroup},
+			wantErr:   assert.NoError,
+			want: map[string][]float64{
+				"Brussels / (unknown)": {0, 1},
+				"Brussels / 85+":       {1, 0},
+				"Flanders / 75-84":     {2, 0},
+				"Flanders / 85+":       {3, 4},
+			},
+		},
+		{
+			name:      "same column",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByRegion, Secondary: sciensano.ByRegion},
+			wantErr:   assert.Error,
+		},
+		{
+			name:      "unsupported secondary column",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByRegion, Secondary: sciensano.ByManufacturer},
+			wantErr:   assert.Error,
+		},
+		{
+			name:      "invalid secondary column",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByRegion, Secondary: sciensano.ByCategory},
+			wantErr:   assert.Error,
+		},
+		{
+			name:      "invalid primary column",
+			breakdown: sciensano.Breakdown{Primary: sciensano.ByManufacturer, Secondary: sciensano.ByRegion},
+			wantErr:   assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := sciensano.SummarizeBreakdown(mortalities, tt.breakdown)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			require.Len(t, summary.GetColumns(), len(tt.want))
+			for column, want := range tt.want {
+				values, ok := summary.GetValues(column)
+				require.True(t, ok, column)
+				assert.Equal(t, want, values, column)
+			}
+		})
+	}
+}
+
+func TestSecondaryBreakdownModes(t *testing.T) {
+	modes := sciensano.SecondaryBreakdownModes()
+	assert.True(t, modes.Contains(sciensano.ByAgeGroup))
+	assert.False(t, modes.Contains(sciensano.Total))
+	assert.False(t, modes.Contains(sciensano.ByCategory))
+}
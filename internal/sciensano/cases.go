@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/clambin/go-common/set"
 	"github.com/clambin/go-common/tabulator"
+	"iter"
+	"slices"
 )
 
 //easyjson:json
@@ -12,6 +14,7 @@ type Case struct {
 	Province  string    `json:"PROVINCE"`
 	Region    string    `json:"REGION"`
 	AgeGroup  string    `json:"AGEGROUP"`
+	Gender    string    `json:"SEX"`
 	Cases     int       `json:"CASES"`
 }
 
@@ -19,14 +22,19 @@ type Case struct {
 type Cases []Case
 
 func CasesValidSummaryModes() set.Set[SummaryColumn] {
-	return set.Create(Total, ByRegion, ByProvince, ByAgeGroup)
+	return set.Create(Total, ByRegion, ByProvince, ByAgeGroup, ByGender)
 }
 
 func (cs Cases) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	return SummarizeCases(slices.Values(cs), summaryColumn)
+}
+
+// SummarizeCases summarizes a stream of cases, without needing to hold all records in memory.
+func SummarizeCases(cases iter.Seq[Case], summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
 	t := tabulator.New()
 
 	columnNames := set.Create[string]()
-	for _, c := range cs {
+	for c := range cases {
 		var columnName string
 		switch summaryColumn {
 		case Total:
@@ -37,6 +45,8 @@ func (cs Cases) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulator, er
 			columnName = c.Province
 		case ByAgeGroup:
 			columnName = c.AgeGroup
+		case ByGender:
+			columnName = c.Gender
 		default:
 			return nil, fmt.Errorf("cases: invalid summary column: %s", summaryColumn.String())
 		}
@@ -53,3 +63,53 @@ func (cs Cases) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulator, er
 
 	return t, nil
 }
+
+type ageGroupShare struct {
+	ageGroup string
+	share    float64
+}
+
+// mortalityAgeGroups maps the age groups of the cases onto the age groups of the mortalities.
+// Age groups that straddle two mortality age groups are split evenly between them.
+var mortalityAgeGroups = map[string][]ageGroupShare{
+	"0-9":   {{ageGroup: "0-24", share: 1}},
+	"10-19": {{ageGroup: "0-24", share: 1}},
+	"20-29": {{ageGroup: "0-24", share: 0.5}, {ageGroup: "25-44", share: 0.5}},
+	"30-39": {{ageGroup: "25-44", share: 1}},
+	"40-49": {{ageGroup: "25-44", share: 0.5}, {ageGroup: "45-64", share: 0.5}},
+	"50-59": {{ageGroup: "45-64", share: 1}},
+	"60-69": {{ageGroup: "45-64", share: 0.5}, {ageGroup: "65-74", share: 0.5}},
+	"70-79": {{ageGroup: "65-74", share: 0.5}, {ageGroup: "75-84", share: 0.5}},
+	"80-89": {{ageGroup: "75-84", share: 0.5}, {ageGroup: "85+", share: 0.5}},
+	"90+":   {{ageGroup: "85+", share: 1}},
+}
+
+// SummarizeForMortalities summarizes the cases so they can be compared with the mortalities.
+// ByAgeGroup uses the age groups of the mortalities. All other summary columns are the same as Summarize.
+func (cs Cases) SummarizeForMortalities(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	summary, err := cs.Summarize(summaryColumn)
+	if err != nil || summaryColumn != ByAgeGroup {
+		return summary, err
+	}
+
+	t := tabulator.New()
+	columnNames := set.Create[string]()
+	timestamps := summary.GetTimestamps()
+	for _, column := range summary.GetColumns() {
+		values, _ := summary.GetValues(column)
+		groups, ok := mortalityAgeGroups[column]
+		if !ok {
+			groups = []ageGroupShare{{ageGroup: column, share: 1}}
+		}
+		for _, group := range groups {
+			if !columnNames.Contains(group.ageGroup) {
+				t.RegisterColumn(group.ageGroup)
+				columnNames.Add(group.ageGroup)
+			}
+			for index, timestamp := range timestamps {
+				t.Add(timestamp, group.ageGroup, values[index]*group.share)
+			}
+		}
+	}
+	return t, nil
+}
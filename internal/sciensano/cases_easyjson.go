@@ -112,6 +112,8 @@ func easyjson69364dd1DecodeGithubComClambinSciensanoInternalSciensano1(in *jlexe
 			out.Region = string(in.String())
 		case "AGEGROUP":
 			out.AgeGroup = string(in.String())
+		case "SEX":
+			out.Gender = string(in.String())
 		case "CASES":
 			out.Cases = int(in.Int())
 		default:
@@ -148,6 +150,11 @@ func easyjson69364dd1EncodeGithubComClambinSciensanoInternalSciensano1(out *jwri
 		out.RawString(prefix)
 		out.String(string(in.AgeGroup))
 	}
+	{
+		const prefix string = ",\"SEX\":"
+		out.RawString(prefix)
+		out.String(string(in.Gender))
+	}
 	{
 		const prefix string = ",\"CASES\":"
 		out.RawString(prefix)
@@ -1,6 +1,7 @@
 package sciensano_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
@@ -9,7 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestCases_Unmarshal(t *testing.T) {
@@ -48,6 +51,21 @@ func BenchmarkCases_Unmarshal_EasyJSON(b *testing.B) {
 	}
 }
 
+// BenchmarkCases_Unmarshal_Decoder decodes the feed one record at a time, as sciensano.Fetcher does.
+// Compare with BenchmarkCases_Unmarshal_EasyJSON, which needs the full response in memory.
+func BenchmarkCases_Unmarshal_Decoder(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("testutil", "testdata", "cases.json"))
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		d := sciensano.NewDecoder[sciensano.Case](bytes.NewReader(content))
+		if _ = slices.Collect(d.All()); d.Err() != nil {
+			b.Fatal(d.Err())
+		}
+	}
+}
+
 func TestCases_Summarize(t *testing.T) {
 	cases := testutil.Cases()
 
@@ -76,6 +94,11 @@ func TestCases_Summarize(t *testing.T) {
 			wantErr:       assert.NoError,
 			want:          wantProvinces,
 		},
+		{
+			summaryColumn: sciensano.ByGender,
+			wantErr:       assert.NoError,
+			want:          []string{"(unknown)", "F", "M"},
+		},
 		{
 			summaryColumn: sciensano.ByManufacturer,
 			wantErr:       assert.Error,
@@ -92,3 +115,29 @@ func TestCases_Summarize(t *testing.T) {
 		})
 	}
 }
+
+func TestCases_SummarizeForMortalities(t *testing.T) {
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cases := sciensano.Cases{
+		{TimeStamp: sciensano.TimeStamp{Time: day}, AgeGroup: "10-19", Cases: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: day}, AgeGroup: "20-29", Cases: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: day}, AgeGroup: "90+", Cases: 4},
+		{TimeStamp: sciensano.TimeStamp{Time: day}, Cases: 1},
+	}
+
+	d, err := cases.SummarizeForMortalities(sciensano.ByAgeGroup)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(unknown)", "0-24", "25-44", "85+"}, d.GetColumns())
+	for column, want := range map[string]float64{"(unknown)": 1, "0-24": 15, "25-44": 5, "85+": 4} {
+		values, ok := d.GetValues(column)
+		require.True(t, ok)
+		assert.Equal(t, []float64{want}, values)
+	}
+
+	d, err = cases.SummarizeForMortalities(sciensano.Total)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Total"}, d.GetColumns())
+
+	_, err = cases.SummarizeForMortalities(sciensano.ByManufacturer)
+	assert.Error(t, err)
+}
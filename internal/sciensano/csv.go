@@ -0,0 +1,110 @@
+package sciensano
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// allCSV decodes a CSV feed. Sciensano uses the same names for the CSV headers as for the JSON attributes,
+// so columns are mapped to fields through their json tags. Fields that implement json.Unmarshaler (e.g. TimeStamp
+// and DoseType) are parsed by their UnmarshalJSON method, so CSV and JSON feeds decode to the same records.
+// Empty values are treated as missing attributes in a JSON feed, i.e. the field keeps its zero value.
+func (d *Decoder[T]) allCSV() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		r := csv.NewReader(d.r)
+		r.ReuseRecord = true
+
+		header, err := r.Read()
+		if err != nil {
+			d.err = fmt.Errorf("csv header: %w", err)
+			return
+		}
+		var setters []csvFieldSetter
+		if setters, d.err = makeCSVFieldSetters[T](header); d.err != nil {
+			return
+		}
+
+		for line := 2; ; line++ {
+			row, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				d.err = fmt.Errorf("csv: %w", err)
+				return
+			}
+			var record T
+			v := reflect.ValueOf(&record).Elem()
+			for i, value := range row {
+				if i >= len(setters) || setters[i] == nil || value == "" {
+					continue
+				}
+				if err = setters[i](v, value); err != nil {
+					d.err = fmt.Errorf("csv line %d, column %s: %w", line, header[i], err)
+					return
+				}
+			}
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+type csvFieldSetter func(record reflect.Value, value string) error
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+func makeCSVFieldSetters[T any](header []string) ([]csvFieldSetter, error) {
+	recordType := reflect.TypeFor[T]()
+	if recordType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: unsupported record type %s", recordType)
+	}
+
+	fields := make(map[string]int)
+	for i := range recordType.NumField() {
+		if tag, _, _ := strings.Cut(recordType.Field(i).Tag.Get("json"), ","); tag != "" && tag != "-" {
+			fields[tag] = i
+		}
+	}
+
+	setters := make([]csvFieldSetter, len(header))
+	for i, column := range header {
+		// some exports start with a UTF-8 byte order mark
+		column = strings.TrimPrefix(column, "\ufeff")
+		index, ok := fields[column]
+		if !ok {
+			continue
+		}
+		field := recordType.Field(index)
+		switch {
+		case reflect.PointerTo(field.Type).Implements(jsonUnmarshalerType):
+			setters[i] = func(record reflect.Value, value string) error {
+				return record.Field(index).Addr().Interface().(json.Unmarshaler).UnmarshalJSON([]byte(strconv.Quote(value)))
+			}
+		case field.Type.Kind() == reflect.String:
+			setters[i] = func(record reflect.Value, value string) error {
+				record.Field(index).SetString(value)
+				return nil
+			}
+		case field.Type.Kind() == reflect.Int:
+			setters[i] = func(record reflect.Value, value string) error {
+				n, err := strconv.Atoi(value)
+				if err == nil {
+					record.Field(index).SetInt(int64(n))
+				}
+				return err
+			}
+		default:
+			return nil, fmt.Errorf("csv: unsupported type %s for field %s", field.Type, field.Name)
+		}
+	}
+	return setters, nil
+}
@@ -0,0 +1,63 @@
+package sciensano_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoder_CSV(t *testing.T) {
+	const input = "\ufeffDATE,REGION,AGEGROUP,SEX,BRAND,DOSE,COUNT\n" +
+		"2021-01-01,Flanders,18-24,F,Pfizer-BioNTech,A,10\n" +
+		"2021-01-02,,85+,M,Moderna,E4+,5\n"
+
+	d := sciensano.NewDecoderForFormat[sciensano.Vaccination](strings.NewReader(input), sciensano.CSV)
+	records := slices.Collect(d.All())
+	require.NoError(t, d.Err())
+
+	assert.Equal(t, []sciensano.Vaccination{
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)}, Manufacturer: "Pfizer-BioNTech", Region: "Flanders", AgeGroup: "18-24", Gender: "F", Dose: sciensano.Partial, Count: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC)}, Manufacturer: "Moderna", AgeGroup: "85+", Gender: "M", Dose: sciensano.Booster4, Count: 5},
+	}, records)
+}
+
+func TestDecoder_CSV_Errors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ``},
+		{name: "invalid date", input: "DATE,CASES\n2021-13,1\n"},
+		{name: "invalid count", input: "DATE,CASES\n2021-01-01,many\n"},
+		{name: "invalid csv", input: "DATE,CASES\n\"2021-01-01,1\n"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := sciensano.NewDecoderForFormat[sciensano.Case](strings.NewReader(tt.input), sciensano.CSV)
+			for range d.All() {
+			}
+			assert.Error(t, d.Err())
+		})
+	}
+}
+
+func TestDecoder_CSV_Types(t *testing.T) {
+	const input = "DATE,PROVINCE,REGION,TOTAL_IN,TOTAL_IN_ICU,TOTAL_IN_RESP,TOTAL_IN_ECMO\n2024-03-01,Antwerpen,Flanders,10,2,1,0\n"
+
+	d := sciensano.NewDecoderForFormat[sciensano.Hospitalisation](strings.NewReader(input), sciensano.CSV)
+	records := slices.Collect(d.All())
+	require.NoError(t, d.Err())
+	require.Len(t, records, 1)
+	assert.Equal(t, 10, records[0].TotalIn)
+	assert.Equal(t, 2, records[0].TotalInICU)
+
+	d2 := sciensano.NewDecoderForFormat[int](strings.NewReader(input), sciensano.CSV)
+	for range d2.All() {
+	}
+	assert.Error(t, d2.Err())
+}
@@ -0,0 +1,61 @@
+package sciensano
+
+import "time"
+
+// Len returns the number of records.
+func (c Cases) Len() int { return len(c) }
+
+// LastTimestamp returns the date of the most recent record.
+func (c Cases) LastTimestamp() time.Time {
+	return lastTimestamp(c, func(r Case) TimeStamp { return r.TimeStamp })
+}
+
+// Len returns the number of records.
+func (h Hospitalisations) Len() int { return len(h) }
+
+// LastTimestamp returns the date of the most recent record.
+func (h Hospitalisations) LastTimestamp() time.Time {
+	return lastTimestamp(h, func(r Hospitalisation) TimeStamp { return r.TimeStamp })
+}
+
+// Len returns the number of records.
+func (m Mortalities) Len() int { return len(m) }
+
+// LastTimestamp returns the date of the most recent record.
+func (m Mortalities) LastTimestamp() time.Time {
+	return lastTimestamp(m, func(r Mortality) TimeStamp { return r.TimeStamp })
+}
+
+// Len returns the number of records.
+func (t TestResults) Len() int { return len(t) }
+
+// LastTimestamp returns the date of the most recent record.
+func (t TestResults) LastTimestamp() time.Time {
+	return lastTimestamp(t, func(r TestResult) TimeStamp { return r.TimeStamp })
+}
+
+// Len returns the number of records.
+func (v Vaccinations) Len() int { return len(v) }
+
+// LastTimestamp returns the date of the most recent record.
+func (v Vaccinations) LastTimestamp() time.Time {
+	return lastTimestamp(v, func(r Vaccination) TimeStamp { return r.TimeStamp })
+}
+
+// Len returns the number of records.
+func (m MunicipalityCases) Len() int { return len(m) }
+
+// LastTimestamp returns the date of the most recent record.
+func (m MunicipalityCases) LastTimestamp() time.Time {
+	return lastTimestamp(m, func(r MunicipalityCase) TimeStamp { return r.TimeStamp })
+}
+
+func lastTimestamp[T any](records []T, getTimestamp func(T) TimeStamp) time.Time {
+	var last time.Time
+	for _, record := range records {
+		if ts := getTimestamp(record).Time; ts.After(last) {
+			last = ts
+		}
+	}
+	return last
+}
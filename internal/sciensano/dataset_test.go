@@ -0,0 +1,22 @@
+package sciensano_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMortalities_LastTimestamp(t *testing.T) {
+	var m sciensano.Mortalities
+	assert.Zero(t, m.Len())
+	assert.True(t, m.LastTimestamp().IsZero())
+
+	m = sciensano.Mortalities{
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)}},
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)}},
+		{TimeStamp: sciensano.TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	assert.Equal(t, 3, m.Len())
+	assert.Equal(t, time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC), m.LastTimestamp())
+}
@@ -0,0 +1,215 @@
+package sciensano
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+	"io"
+	"iter"
+)
+
+// Decoder reads the records of a feed from a reader, one record at a time, so the full response doesn't need to be
+// buffered before it's decoded. Callers that only need a summary of the feed (e.g. SummarizeVaccinations) can consume
+// the records directly, without ever holding the full slice of records.
+//
+// Any error encountered while decoding ends the iteration and is returned by Err.
+type Decoder[T any] struct {
+	r      io.Reader
+	format Format
+	err    error
+}
+
+// NewDecoder returns a Decoder that reads a JSON array of records from r.
+func NewDecoder[T any](r io.Reader) *Decoder[T] {
+	return NewDecoderForFormat[T](r, JSON)
+}
+
+// NewDecoderForFormat returns a Decoder that reads records in the specified format from r.
+func NewDecoderForFormat[T any](r io.Reader, format Format) *Decoder[T] {
+	return &Decoder[T]{r: r, format: format}
+}
+
+// All returns an iterator over all records in the input.
+func (d *Decoder[T]) All() iter.Seq[T] {
+	switch d.format {
+	case JSON:
+		return d.allJSON()
+	case CSV:
+		return d.allCSV()
+	default:
+		return func(_ func(T) bool) {
+			d.err = fmt.Errorf("unsupported format: %s", d.format)
+		}
+	}
+}
+
+// Err returns the first error encountered while decoding the input.
+func (d *Decoder[T]) Err() error {
+	return d.err
+}
+
+// allJSON splits the array into its records with a single pass over the input and decodes each record separately.
+// Records generated by easyjson (e.g. Vaccination and Case) are decoded with easyjson: going through json.Decoder
+// would scan each record twice before easyjson even sees it.
+func (d *Decoder[T]) allJSON() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s := arrayScanner{r: bufio.NewReaderSize(d.r, 64*1024)}
+		var lexer jlexer.Lexer
+		record := new(T)
+		for {
+			raw, ok, err := s.next()
+			if err != nil {
+				d.err = err
+				return
+			}
+			if !ok {
+				return
+			}
+			var zero T
+			*record = zero
+			if d.err = unmarshalRecord(&lexer, raw, record); d.err != nil {
+				return
+			}
+			if !yield(*record) {
+				return
+			}
+		}
+	}
+}
+
+func unmarshalRecord[T any](lexer *jlexer.Lexer, raw []byte, record *T) error {
+	if u, ok := any(record).(easyjson.Unmarshaler); ok {
+		*lexer = jlexer.Lexer{Data: raw}
+		u.UnmarshalEasyJSON(lexer)
+		return lexer.Error()
+	}
+	return json.Unmarshal(raw, record)
+}
+
+// arrayScanner returns the elements of a JSON array of objects, one at a time.
+// It only tracks enough of the JSON syntax to find where each object ends: the objects themselves are validated when they're decoded.
+type arrayScanner struct {
+	r       *bufio.Reader
+	started bool
+	done    bool
+	buf     []byte
+}
+
+// next returns the next element of the array, or false if the array has no more elements. The returned slice is only
+// valid until the next call.
+func (s *arrayScanner) next() ([]byte, bool, error) {
+	if s.done {
+		return nil, false, nil
+	}
+	c, err := s.readNonSpace()
+	if err != nil {
+		return nil, false, err
+	}
+	if !s.started {
+		if c != '[' {
+			return nil, false, fmt.Errorf("invalid token: expected [, got %q", c)
+		}
+		s.started = true
+		if c, err = s.readNonSpace(); err != nil {
+			return nil, false, err
+		}
+		if c == ']' {
+			s.done = true
+			return nil, false, nil
+		}
+	} else {
+		switch c {
+		case ']':
+			s.done = true
+			return nil, false, nil
+		case ',':
+			if c, err = s.readNonSpace(); err != nil {
+				return nil, false, err
+			}
+		default:
+			return nil, false, fmt.Errorf("invalid token: expected , or ], got %q", c)
+		}
+	}
+	if c != '{' {
+		return nil, false, fmt.Errorf("invalid record: expected {, got %q", c)
+	}
+	return s.readObject()
+}
+
+func (s *arrayScanner) readObject() ([]byte, bool, error) {
+	s.buf = append(s.buf[:0], '{')
+	depth := 1
+	var inString bool
+	for depth > 0 {
+		// scan whatever is buffered, rather than reading one byte at a time
+		chunk, err := s.r.Peek(max(s.r.Buffered(), 1))
+		if len(chunk) == 0 {
+			return nil, false, unexpectedEOF(err)
+		}
+		var n int
+		for n < len(chunk) && depth > 0 {
+			if inString {
+				i := bytes.IndexByte(chunk[n:], '"')
+				if i < 0 {
+					n = len(chunk)
+					break
+				}
+				n += i + 1
+				inString = s.isEscaped(chunk, n-1)
+				continue
+			}
+			switch chunk[n] {
+			case '"':
+				inString = true
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			n++
+		}
+		s.buf = append(s.buf, chunk[:n]...)
+		_, _ = s.r.Discard(n)
+	}
+	return s.buf, true, nil
+}
+
+// isEscaped reports whether the quote at chunk[i] is escaped, i.e. preceded by an odd number of backslashes.
+// The backslashes may continue into the part of the object that was read before chunk.
+func (s *arrayScanner) isEscaped(chunk []byte, i int) bool {
+	var backslashes int
+	for i--; i >= 0 && chunk[i] == '\\'; i-- {
+		backslashes++
+	}
+	if i < 0 {
+		for j := len(s.buf) - 1; j >= 0 && s.buf[j] == '\\'; j-- {
+			backslashes++
+		}
+	}
+	return backslashes%2 == 1
+}
+
+func (s *arrayScanner) readNonSpace() (byte, error) {
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return c, nil
+		}
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
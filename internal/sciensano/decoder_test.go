@@ -0,0 +1,137 @@
+package sciensano_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid",
+			input:   `[{"DATE":"2024-03-01","REGION":"Flanders","AGEGROUP":"85+","DEATHS":1},{"DATE":"2024-03-02","REGION":"Wallonia","AGEGROUP":"85+","DEATHS":2}]`,
+			want:    2,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty",
+			input:   `[]`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "not an array",
+			input:   `{"DATE":"2024-03-01"}`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid record",
+			input:   `[{"DATE":"2024-03-01","DEATHS":1},{"DATE":"invalid","DEATHS":1}]`,
+			want:    1,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "truncated",
+			input:   `[{"DATE":"2024-03-01","DEATHS":1}`,
+			want:    1,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "not an array of records",
+			input:   `[1,2]`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing separator",
+			input:   `[{"DATE":"2024-03-01","DEATHS":1} {"DATE":"2024-03-02","DEATHS":1}]`,
+			want:    1,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := sciensano.NewDecoder[sciensano.Mortality](strings.NewReader(tt.input))
+			var count int
+			for range d.All() {
+				count++
+			}
+			assert.Equal(t, tt.want, count)
+			tt.wantErr(t, d.Err())
+		})
+	}
+}
+
+func TestDecoder_Strings(t *testing.T) {
+	const input = ` [ {"DATE":"2024-03-01","REGION":"{[\\\"}]","PROVINCE":"\\\\","CASES":1} ,
+	{"DATE":"2024-03-02","REGION":"Flanders","PROVINCE":"Antwerpen","CASES":2} ] `
+
+	for name, r := range map[string]func() io.Reader{
+		"buffered": func() io.Reader { return strings.NewReader(input) },
+		// records are split across reads, so the decoder needs to keep track of strings & escapes across reads
+		"one byte at a time": func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := sciensano.NewDecoder[sciensano.Case](r())
+			cases := slices.Collect(d.All())
+			require.NoError(t, d.Err())
+			require.Len(t, cases, 2)
+			assert.Equal(t, `{[\"}]`, cases[0].Region)
+			assert.Equal(t, `\\`, cases[0].Province)
+			assert.Equal(t, 1, cases[0].Cases)
+			assert.Equal(t, "Flanders", cases[1].Region)
+			assert.Equal(t, 2, cases[1].Cases)
+		})
+	}
+}
+
+func TestDecoder_Break(t *testing.T) {
+	d := sciensano.NewDecoder[sciensano.Mortality](strings.NewReader(`[{"DATE":"2024-03-01","DEATHS":1},{"DATE":"2024-03-02","DEATHS":1}]`))
+	for m := range d.All() {
+		assert.Equal(t, 1, m.Deaths)
+		break
+	}
+	assert.NoError(t, d.Err())
+}
+
+func TestSummarizeVaccinations(t *testing.T) {
+	f, err := os.Open(filepath.Join("testutil", "testdata", "vaccinations.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	d := sciensano.NewDecoder[sciensano.Vaccination](f)
+	streamed, err := sciensano.SummarizeVaccinations(d.All(), sciensano.ByRegion)
+	require.NoError(t, err)
+	require.NoError(t, d.Err())
+
+	assert.Equal(t, []string{"(unknown)", "Brussels", "Flanders", "Ostbelgien", "Wallonia"}, streamed.GetColumns())
+	assert.NotZero(t, streamed.Size())
+}
+
+func BenchmarkDecoder_Vaccinations(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("testutil", "testdata", "vaccinations.json"))
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		d := sciensano.NewDecoder[sciensano.Vaccination](strings.NewReader(string(content)))
+		if _, err = sciensano.SummarizeVaccinations(d.All(), sciensano.Total); err != nil {
+			b.Fatal(err)
+		}
+		if err = d.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
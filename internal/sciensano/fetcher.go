@@ -1,63 +1,159 @@
 package sciensano
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/mailru/easyjson"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 )
 
+// Fetcher retrieves the records for a Sciensano endpoint.
+//
+// Fetcher remembers the ETag and Last-Modified headers of the previous response and sends them back
+// as If-None-Match / If-Modified-Since, so an unchanged dataset only costs a single 304 round trip.
+// If the server doesn't send any validators, Fetcher compares a hash of the response body instead.
 type Fetcher[T any] struct {
-	Target string
-	Client *http.Client
+	Target       string
+	Client       *http.Client
+	Format       Format
+	etag         string
+	lastModified string
+	hash         []byte
+	lock         sync.Mutex
 }
 
-func (f *Fetcher[T]) GetLastModified(ctx context.Context) (time.Time, error) {
-	req, _ := http.NewRequestWithContext(ctx, http.MethodHead, f.Target, nil)
-	resp, err := f.Client.Do(req)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("%s: HEAD failed: %w", f.Target, err)
-	}
-	_ = resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return time.Time{}, fmt.Errorf("%s: GET failed: %s", f.Target, resp.Status)
-	}
-	return time.Parse(time.RFC1123, resp.Header.Get("Last-Modified"))
-}
+// Fetch retrieves the records if they changed since the previous call. It returns the records, the time they were last modified
+// and whether they changed. If the server doesn't report a modification time, the time of the call is used instead.
+func (f *Fetcher[T]) Fetch(ctx context.Context) (T, time.Time, bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 
-func (f *Fetcher[T]) Fetch(ctx context.Context) (T, error) {
+	var records T
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, f.Target, nil)
+	if f.etag != "" {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+	if f.lastModified != "" {
+		req.Header.Set("If-Modified-Since", f.lastModified)
+	}
 	resp, err := f.Client.Do(req)
-	var records T
 	if err != nil {
-		return records, fmt.Errorf("%s: GET failed: %w", f.Target, err)
+		return records, time.Time{}, false, fmt.Errorf("%s: GET failed: %w", f.Target, err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
-	if resp.StatusCode != http.StatusOK {
-		return records, fmt.Errorf("%s: GET failed: %s", f.Target, resp.Status)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return records, time.Time{}, false, nil
+	default:
+		return records, time.Time{}, false, fmt.Errorf("%s: GET failed: %w", f.Target, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	h := sha256.New()
+	if records, err = unmarshal[T](io.TeeReader(resp.Body, h), f.Format); err != nil {
+		return records, time.Time{}, false, fmt.Errorf("%s: decode: %w", f.Target, err)
+	}
+	hash := h.Sum(nil)
+
+	if etag == "" && lastModified == "" && bytes.Equal(hash, f.hash) {
+		return records, time.Time{}, false, nil
 	}
-	return unmarshal[T](resp.Body)
+	f.etag, f.lastModified, f.hash = etag, lastModified, hash
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		modified = time.Now()
+	}
+	return records, modified, true, nil
 }
 
-func unmarshal[T any](r io.Reader) (v T, err error) {
+// SetLastModified sets the modification time of records that were already retrieved, e.g. records loaded from a cache.
+// Unless Fetcher already has validators from a previous response, the next call sends it as If-Modified-Since,
+// so the records aren't downloaded again if they didn't change.
+func (f *Fetcher[T]) SetLastModified(lastModified time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.etag == "" && f.lastModified == "" && !lastModified.IsZero() {
+		f.lastModified = lastModified.UTC().Format(http.TimeFormat)
+	}
+}
+
+// StatusError is returned when the server responds with an unexpected HTTP status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return e.Status
+}
+
+// IsRetryable reports whether a failed Fetch may succeed if it is retried: server errors, throttling and network errors
+// are considered transient. Client errors (e.g. 404), invalid data and cancelled contexts are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func unmarshal[T any](r io.Reader, format Format) (v T, err error) {
+	// records are decoded one at a time, so we don't need to buffer the full response. They are still collected into
+	// a slice: the datasource keeps the records, to publish them to each of its reporters and to cache them.
 	switch any(v).(type) {
-	case Vaccinations:
-		var v2 Vaccinations
-		if err = easyjson.UnmarshalFromReader(r, &v2); err == nil {
-			v = any(v2).(T)
-		}
 	case Cases:
-		var v2 Cases
-		if err = easyjson.UnmarshalFromReader(r, &v2); err == nil {
-			v = any(v2).(T)
-		}
-	default:
-		err = json.NewDecoder(r).Decode(&v)
+		records, err := decodeAll[Case](r, format)
+		return any(Cases(records)).(T), err
+	case Hospitalisations:
+		records, err := decodeAll[Hospitalisation](r, format)
+		return any(Hospitalisations(records)).(T), err
+	case Mortalities:
+		records, err := decodeAll[Mortality](r, format)
+		return any(Mortalities(records)).(T), err
+	case TestResults:
+		records, err := decodeAll[TestResult](r, format)
+		return any(TestResults(records)).(T), err
+	case Vaccinations:
+		records, err := decodeAll[Vaccination](r, format)
+		return any(Vaccinations(records)).(T), err
+	case MunicipalityCases:
+		records, err := decodeAll[MunicipalityCase](r, format)
+		return any(MunicipalityCases(records)).(T), err
+	}
+	if format != JSON {
+		return v, fmt.Errorf("unsupported format for %T: %s", v, format)
 	}
+	err = json.NewDecoder(r).Decode(&v)
 	return v, err
 }
+
+func decodeAll[T any](r io.Reader, format Format) ([]T, error) {
+	d := NewDecoderForFormat[T](r, format)
+	records := slices.Collect(d.All())
+	return records, d.Err()
+}
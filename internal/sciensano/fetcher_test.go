@@ -1,7 +1,10 @@
 package sciensano
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -9,6 +12,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,14 +28,10 @@ func TestFetcher_Cases(t *testing.T) {
 
 	assert.Equal(t, "COVID19BE_CASES_AGESEX.json", filepath.Base(f.Target))
 
-	ctx := context.Background()
-
-	timestamp, err := f.GetLastModified(ctx)
+	entries, timestamp, changed, err := f.Fetch(context.Background())
 	require.NoError(t, err)
+	assert.True(t, changed)
 	assert.NotZero(t, timestamp)
-
-	entries, err := f.Fetch(context.Background())
-	require.NoError(t, err)
 	assert.NotEmpty(t, entries)
 }
 
@@ -46,14 +46,10 @@ func TestFetcher_Vaccinations(t *testing.T) {
 
 	assert.Equal(t, "COVID19BE_VACC.json", filepath.Base(f.Target))
 
-	ctx := context.Background()
-
-	timestamp, err := f.GetLastModified(ctx)
+	entries, timestamp, changed, err := f.Fetch(context.Background())
 	require.NoError(t, err)
+	assert.True(t, changed)
 	assert.NotZero(t, timestamp)
-
-	entries, err := f.Fetch(context.Background())
-	require.NoError(t, err)
 	assert.NotEmpty(t, entries)
 }
 
@@ -68,26 +64,133 @@ func TestFetcher_TestResults(t *testing.T) {
 
 	assert.Equal(t, "COVID19BE_tests.json", filepath.Base(f.Target))
 
+	entries, timestamp, changed, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.NotZero(t, timestamp)
+	assert.NotEmpty(t, entries)
+}
+
+func TestFetcher_Conditional(t *testing.T) {
+	var etag string
+	var calls, notModified int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`[{"DATE":"2024-03-01","REGION":"Flanders","AGEGROUP":"85+","DEATHS":1}]`))
+	}))
+	defer s.Close()
+
+	f := Fetcher[Mortalities]{Target: s.URL, Client: http.DefaultClient}
 	ctx := context.Background()
 
-	timestamp, err := f.GetLastModified(ctx)
+	// no validators: fall back to comparing the body
+	entries, timestamp, changed, err := f.Fetch(ctx)
 	require.NoError(t, err)
+	assert.True(t, changed)
 	assert.NotZero(t, timestamp)
+	assert.Len(t, entries, 1)
 
-	entries, err := f.Fetch(context.Background())
+	_, _, changed, err = f.Fetch(ctx)
 	require.NoError(t, err)
-	assert.NotEmpty(t, entries)
+	assert.False(t, changed)
+
+	// server starts sending an ETag: next call gets the data, the one after that gets a 304
+	etag = `"v2"`
+	_, _, changed, err = f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	_, _, changed, err = f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 1, notModified)
 }
 
-func handler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodHead {
-		w.Header().Add("Last-Modified", time.Now().Format(time.RFC1123))
-		return
+func TestFetcher_LastModified(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(handler))
+	defer s.Close()
+
+	f := Fetcher[Mortalities]{Target: MustGetURL(s.URL, MortalitiesEndpoint), Client: http.DefaultClient}
+	ctx := context.Background()
+
+	_, timestamp, changed, err := f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, lastModified, timestamp.UTC())
+
+	_, _, changed, err = f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestFetcher_SetLastModified(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(handler))
+	defer s.Close()
+
+	// records loaded from a cache don't need to be downloaded again if they didn't change
+	f := Fetcher[Mortalities]{Target: MustGetURL(s.URL, MortalitiesEndpoint), Client: http.DefaultClient}
+	f.SetLastModified(lastModified)
+	_, _, changed, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// older cached records are replaced
+	f = Fetcher[Mortalities]{Target: MustGetURL(s.URL, MortalitiesEndpoint), Client: http.DefaultClient}
+	f.SetLastModified(lastModified.Add(-time.Hour))
+	_, timestamp, changed, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, lastModified, timestamp.UTC())
+}
+
+func TestFetcher_Errors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(handler))
+	defer s.Close()
+
+	f := Fetcher[Mortalities]{Target: s.URL + "/invalid", Client: http.DefaultClient}
+	_, _, _, err := f.Fetch(context.Background())
+	assert.Error(t, err)
+	assert.False(t, IsRetryable(err))
+
+	s.Close()
+	f.Target = MustGetURL(s.URL, MortalitiesEndpoint)
+	_, _, _, err = f.Fetch(context.Background())
+	assert.Error(t, err)
+	assert.True(t, IsRetryable(err))
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error", err: nil, want: false},
+		{name: "server error", err: fmt.Errorf("GET failed: %w", &StatusError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}), want: true},
+		{name: "throttled", err: &StatusError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, want: true},
+		{name: "not found", err: &StatusError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, want: false},
+		{name: "timeout", err: fmt.Errorf("GET failed: %w", context.DeadlineExceeded), want: true},
+		{name: "cancelled", err: fmt.Errorf("GET failed: %w", context.Canceled), want: false},
+		{name: "decode", err: errors.New("decode: invalid timestamp"), want: false},
 	}
-	if r.Method != http.MethodGet {
-		http.Error(w, "invalid http method", http.StatusBadRequest)
-		return
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
 	}
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
 	endpoint, ok := getEndpoint(r.URL.Path)
 	if !ok {
 		http.Error(w, r.URL.Path+" not found", http.StatusNotFound)
@@ -104,18 +207,22 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, _ = w.Write(content)
+	http.ServeContent(w, r, filename, lastModified, bytes.NewReader(content))
 }
 
+var lastModified = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
 var filenames = map[Endpoint]string{
-	CasesEndpoint:            "cases.json",
-	HospitalisationsEndpoint: "hospitalisations.json",
-	MortalitiesEndpoint:      "mortalities.json",
-	TestResultsEndpoint:      "testResults.json",
-	VaccinationsEndpoint:     "vaccinations.json",
+	CasesEndpoint:             "cases.json",
+	HospitalisationsEndpoint:  "hospitalisations.json",
+	MortalitiesEndpoint:       "mortalities.json",
+	TestResultsEndpoint:       "testResults.json",
+	VaccinationsEndpoint:      "vaccinations.json",
+	MunicipalityCasesEndpoint: "municipalityCases.json",
 }
 
 func getEndpoint(s string) (Endpoint, bool) {
+	s = strings.TrimSuffix(s, path.Ext(s))
 	for endpoint, pathName := range routes {
 		if s == pathName {
 			return endpoint, true
@@ -123,3 +230,23 @@ func getEndpoint(s string) (Endpoint, bool) {
 	}
 	return -1, false
 }
+
+func TestFetcher_CSV(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Data/COVID19BE_MORT.csv" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("DATE,REGION,AGEGROUP,SEX,DEATHS\n2024-03-01,Flanders,85+,F,1\n2024-03-01,Wallonia,85+,M,2\n"))
+	}))
+	defer s.Close()
+
+	f := Fetcher[Mortalities]{Target: MustGetURLForFormat(s.URL, MortalitiesEndpoint, CSV), Client: http.DefaultClient, Format: CSV}
+	entries, _, changed, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, Mortalities{
+		{TimeStamp: TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}, Region: "Flanders", AgeGroup: "85+", Deaths: 1},
+		{TimeStamp: TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}, Region: "Wallonia", AgeGroup: "85+", Deaths: 2},
+	}, entries)
+}
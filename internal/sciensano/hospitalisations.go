@@ -14,6 +14,9 @@ type Hospitalisation struct {
 	TotalInICU  int       `json:"TOTAL_IN_ICU"`
 	TotalInResp int       `json:"TOTAL_IN_RESP"`
 	TotalInECMO int       `json:"TOTAL_IN_ECMO"`
+	NewIn       int       `json:"NEW_IN"`
+	NewOut      int       `json:"NEW_OUT"`
+	NrReporting int       `json:"NR_REPORTING"`
 }
 
 type Hospitalisations []Hospitalisation
@@ -27,6 +30,10 @@ func (h Hospitalisations) Summarize(summaryColumn SummaryColumn) (*tabulator.Tab
 		return h.Categorize(), nil
 	}
 
+	return h.summarize(summaryColumn, func(hospitalisation Hospitalisation) int { return hospitalisation.TotalIn })
+}
+
+func (h Hospitalisations) summarize(summaryColumn SummaryColumn, getValue func(Hospitalisation) int) (*tabulator.Tabulator, error) {
 	t := tabulator.New()
 
 	columnNames := set.Create[string]()
@@ -50,20 +57,65 @@ func (h Hospitalisations) Summarize(summaryColumn SummaryColumn) (*tabulator.Tab
 			columnNames.Add(columnName)
 		}
 
-		t.Add(hospitalisation.TimeStamp.Time, columnName, float64(hospitalisation.TotalIn))
+		t.Add(hospitalisation.TimeStamp.Time, columnName, float64(getValue(hospitalisation)))
 	}
 
 	return t, nil
 }
 
+// SummarizeAdmissions summarizes the daily number of new hospital admissions.
+func (h Hospitalisations) SummarizeAdmissions(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	return h.summarize(summaryColumn, func(hospitalisation Hospitalisation) int { return hospitalisation.NewIn })
+}
+
+// SummarizePerHospital returns the summary, divided by the number of hospitals that reported figures that day.
+// This corrects for hospitals that start or stop reporting. Days without reporting hospitals are reported as zero.
+func (h Hospitalisations) SummarizePerHospital(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	summary, err := h.Summarize(summaryColumn)
+	if err != nil {
+		return nil, err
+	}
+	reportingColumn := summaryColumn
+	if summaryColumn == ByCategory {
+		reportingColumn = Total
+	}
+	reporting, err := h.summarize(reportingColumn, func(hospitalisation Hospitalisation) int { return hospitalisation.NrReporting })
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := summary.GetTimestamps()
+	columns := summary.GetColumns()
+	normalized := tabulator.New(columns...)
+	for _, column := range columns {
+		values, _ := summary.GetValues(column)
+		hospitalsColumn := column
+		if summaryColumn == ByCategory {
+			hospitalsColumn = "Total"
+		}
+		hospitals, _ := reporting.GetValues(hospitalsColumn)
+		for index, timestamp := range timestamps {
+			var value float64
+			if hospitals[index] > 0 {
+				value = values[index] / hospitals[index]
+			}
+			normalized.Set(timestamp, column, value)
+		}
+	}
+	return normalized, nil
+}
+
+// Categorize returns the hospital occupancy (in, inICU, inResp, inECMO) and the daily admissions and discharges (newIn, newOut).
 func (h Hospitalisations) Categorize() *tabulator.Tabulator {
-	t := tabulator.New("in", "inICU", "inResp", "inECMO")
+	t := tabulator.New("in", "inICU", "inResp", "inECMO", "newIn", "newOut")
 
 	for _, hospitalisation := range h {
 		t.Add(hospitalisation.TimeStamp.Time, "in", float64(hospitalisation.TotalIn))
 		t.Add(hospitalisation.TimeStamp.Time, "inICU", float64(hospitalisation.TotalInICU))
 		t.Add(hospitalisation.TimeStamp.Time, "inResp", float64(hospitalisation.TotalInResp))
 		t.Add(hospitalisation.TimeStamp.Time, "inECMO", float64(hospitalisation.TotalInECMO))
+		t.Add(hospitalisation.TimeStamp.Time, "newIn", float64(hospitalisation.NewIn))
+		t.Add(hospitalisation.TimeStamp.Time, "newOut", float64(hospitalisation.NewOut))
 	}
 
 	return t
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestHospitalisations_Unmarshal(t *testing.T) {
@@ -62,7 +63,7 @@ func TestHospitalisations_Summarize(t *testing.T) {
 		{
 			summaryColumn: sciensano.ByCategory,
 			wantErr:       assert.NoError,
-			want:          []string{"in", "inECMO", "inICU", "inResp"},
+			want:          []string{"in", "inECMO", "inICU", "inResp", "newIn", "newOut"},
 		},
 		{
 			summaryColumn: sciensano.ByManufacturer,
@@ -82,3 +83,56 @@ func TestHospitalisations_Summarize(t *testing.T) {
 		})
 	}
 }
+
+func TestHospitalisations_SummarizePerHospital(t *testing.T) {
+	day1 := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	hospitalisations := sciensano.Hospitalisations{
+		{TimeStamp: sciensano.TimeStamp{Time: day1}, Region: "Flanders", TotalIn: 100, NewIn: 10, NrReporting: 10},
+		{TimeStamp: sciensano.TimeStamp{Time: day1}, Region: "Wallonia", TotalIn: 50, NewIn: 5, NrReporting: 5},
+		{TimeStamp: sciensano.TimeStamp{Time: day2}, Region: "Flanders", TotalIn: 100, NewIn: 20, NrReporting: 20},
+		{TimeStamp: sciensano.TimeStamp{Time: day2}, Region: "Wallonia", TotalIn: 50, NewIn: 10},
+	}
+
+	testCases := []struct {
+		summaryColumn sciensano.SummaryColumn
+		wantErr       assert.ErrorAssertionFunc
+		want          map[string][]float64
+	}{
+		{
+			summaryColumn: sciensano.Total,
+			wantErr:       assert.NoError,
+			want:          map[string][]float64{"Total": {10, 7.5}},
+		},
+		{
+			summaryColumn: sciensano.ByRegion,
+			wantErr:       assert.NoError,
+			want:          map[string][]float64{"Flanders": {10, 5}, "Wallonia": {10, 0}},
+		},
+		{
+			summaryColumn: sciensano.ByCategory,
+			wantErr:       assert.NoError,
+			want:          map[string][]float64{"in": {10, 7.5}, "newIn": {1, 1.5}},
+		},
+		{
+			summaryColumn: sciensano.ByAgeGroup,
+			wantErr:       assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.summaryColumn.String(), func(t *testing.T) {
+			d, err := hospitalisations.SummarizePerHospital(tt.summaryColumn)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, []time.Time{day1, day2}, d.GetTimestamps())
+			for column, want := range tt.want {
+				values, ok := d.GetValues(column)
+				require.True(t, ok)
+				assert.Equal(t, want, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package sciensano
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// FileFetcher reads the records for a Sciensano endpoint from a local file, e.g. for deployments that can't reach
+// the Sciensano API. The file's modification time is used as the time the records were last modified.
+type FileFetcher[T any] struct {
+	Path   string
+	Format Format
+	mtime  time.Time
+	lock   sync.Mutex
+}
+
+// Fetch reads the records if the file was modified since the previous call.
+func (f *FileFetcher[T]) Fetch(_ context.Context) (T, time.Time, bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var records T
+	stats, err := os.Stat(f.Path)
+	if err != nil {
+		return records, time.Time{}, false, err
+	}
+	if !stats.ModTime().After(f.mtime) {
+		return records, time.Time{}, false, nil
+	}
+
+	r, err := os.Open(f.Path)
+	if err != nil {
+		return records, time.Time{}, false, err
+	}
+	defer func() { _ = r.Close() }()
+
+	if records, err = unmarshal[T](r, f.Format); err != nil {
+		return records, time.Time{}, false, fmt.Errorf("%s: decode: %w", f.Path, err)
+	}
+	f.mtime = stats.ModTime()
+	return records, f.mtime, true, nil
+}
+
+// ArchiveFetcher reads the records for a Sciensano endpoint from a tarball (optionally gzip-compressed) of snapshot files.
+// The archive may hold several snapshots of the same file, in which case the one with the latest modification time is used.
+type ArchiveFetcher[T any] struct {
+	Path     string
+	Filename string
+	Format   Format
+	archived time.Time
+	mtime    time.Time
+	lock     sync.Mutex
+}
+
+// Fetch reads the records if the archive holds a more recent snapshot than the one read by the previous call.
+func (f *ArchiveFetcher[T]) Fetch(_ context.Context) (T, time.Time, bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var records T
+	stats, err := os.Stat(f.Path)
+	if err != nil {
+		return records, time.Time{}, false, err
+	}
+	if !stats.ModTime().After(f.archived) {
+		return records, time.Time{}, false, nil
+	}
+
+	var latest time.Time
+	err = f.walk(func(h *tar.Header, _ io.Reader) bool {
+		if h.ModTime.After(latest) {
+			latest = h.ModTime
+		}
+		return true
+	})
+	if err != nil {
+		return records, time.Time{}, false, err
+	}
+	if latest.IsZero() {
+		return records, time.Time{}, false, fmt.Errorf("%s: %s not found", f.Path, f.Filename)
+	}
+	if !latest.After(f.mtime) {
+		f.archived = stats.ModTime()
+		return records, time.Time{}, false, nil
+	}
+
+	var decodeErr error
+	err = f.walk(func(h *tar.Header, r io.Reader) bool {
+		if !h.ModTime.Equal(latest) {
+			return true
+		}
+		records, decodeErr = unmarshal[T](r, f.Format)
+		return false
+	})
+	if err = errors.Join(err, decodeErr); err != nil {
+		return records, time.Time{}, false, fmt.Errorf("%s: %s: %w", f.Path, f.Filename, err)
+	}
+	f.archived, f.mtime = stats.ModTime(), latest
+	return records, latest, true, nil
+}
+
+// walk calls fn for each regular file in the archive with the requested filename, until fn returns false.
+func (f *ArchiveFetcher[T]) walk(fn func(*tar.Header, io.Reader) bool) error {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	r, err := decompress(bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Path, err)
+	}
+	archive := tar.NewReader(r)
+	for {
+		h, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+		if h.Typeflag != tar.TypeReg || path.Base(h.Name) != f.Filename {
+			continue
+		}
+		if !fn(h, archive) {
+			return nil
+		}
+	}
+}
+
+func decompress(r *bufio.Reader) (io.Reader, error) {
+	magic, err := r.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(r)
+	}
+	return r, nil
+}
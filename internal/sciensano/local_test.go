@@ -0,0 +1,99 @@
+package sciensano
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const mortalitiesSnapshot = `[{"DATE":"2024-03-01","REGION":"Flanders","AGEGROUP":"85+","DEATHS":1}]`
+
+func TestFileFetcher(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "COVID19BE_MORT.json")
+	require.NoError(t, os.WriteFile(filename, []byte(mortalitiesSnapshot), 0644))
+	mtime := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+
+	f := FileFetcher[Mortalities]{Path: filename}
+	ctx := context.Background()
+
+	records, timestamp, changed, err := f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, mtime, timestamp.UTC())
+	assert.Len(t, records, 1)
+
+	_, _, changed, err = f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	mtime = mtime.Add(time.Hour)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+	_, timestamp, changed, err = f.Fetch(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, mtime, timestamp.UTC())
+
+	f = FileFetcher[Mortalities]{Path: filename + ".missing"}
+	_, _, _, err = f.Fetch(ctx)
+	assert.Error(t, err)
+}
+
+func TestArchiveFetcher(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		t.Run(map[bool]string{false: "tar", true: "tar.gz"}[compressed], func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "snapshots.tar")
+			older := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+			newer := older.Add(24 * time.Hour)
+			writeArchive(t, filename, compressed, map[string]time.Time{
+				"2024-03-01/COVID19BE_MORT.json": older,
+				"2024-03-02/COVID19BE_MORT.json": newer,
+			})
+
+			f := ArchiveFetcher[Mortalities]{Path: filename, Filename: "COVID19BE_MORT.json"}
+			ctx := context.Background()
+
+			records, timestamp, changed, err := f.Fetch(ctx)
+			require.NoError(t, err)
+			assert.True(t, changed)
+			assert.Equal(t, newer, timestamp.UTC())
+			assert.Len(t, records, 1)
+
+			_, _, changed, err = f.Fetch(ctx)
+			require.NoError(t, err)
+			assert.False(t, changed)
+
+			f = ArchiveFetcher[Mortalities]{Path: filename, Filename: "COVID19BE_HOSP.json"}
+			_, _, _, err = f.Fetch(ctx)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func writeArchive(t *testing.T, filename string, compressed bool, entries map[string]time.Time) {
+	t.Helper()
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+
+	var w io.Writer = f
+	if compressed {
+		gz := gzip.NewWriter(f)
+		defer func() { require.NoError(t, gz.Close()) }()
+		w = gz
+	}
+	archive := tar.NewWriter(w)
+	for name, mtime := range entries {
+		require.NoError(t, archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(mortalitiesSnapshot)), ModTime: mtime, Typeflag: tar.TypeReg}))
+		_, err = archive.Write([]byte(mortalitiesSnapshot))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+}
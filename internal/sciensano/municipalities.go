@@ -0,0 +1,89 @@
+package sciensano
+
+import (
+	"fmt"
+	"github.com/clambin/go-common/set"
+	"github.com/clambin/go-common/tabulator"
+	"strconv"
+	"strings"
+)
+
+type MunicipalityCase struct {
+	TimeStamp TimeStamp `json:"DATE"`
+	NIS       NISCode   `json:"NIS5"`
+	NameNL    string    `json:"TX_DESCR_NL"`
+	NameFR    string    `json:"TX_DESCR_FR"`
+	Province  string    `json:"PROVINCE"`
+	Region    string    `json:"REGION"`
+	Cases     CaseCount `json:"CASES"`
+}
+
+type MunicipalityCases []MunicipalityCase
+
+func MunicipalityCasesValidSummaryModes() set.Set[SummaryColumn] {
+	return set.Create(Total, ByRegion, ByProvince, ByMunicipality)
+}
+
+func (m MunicipalityCases) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	t := tabulator.New()
+
+	columnNames := set.Create[string]()
+	for _, c := range m {
+		var columnName string
+		switch summaryColumn {
+		case Total:
+			columnName = "Total"
+		case ByRegion:
+			columnName = c.Region
+		case ByProvince:
+			columnName = c.Province
+		case ByMunicipality:
+			columnName = string(c.NIS)
+		default:
+			return nil, fmt.Errorf("municipality cases: invalid summary column: %s", summaryColumn.String())
+		}
+		if columnName == "" {
+			columnName = "(unknown)"
+		}
+		if !columnNames.Contains(columnName) {
+			t.RegisterColumn(columnName)
+			columnNames.Add(columnName)
+		}
+
+		t.Add(c.TimeStamp.Time, columnName, float64(c.Cases))
+	}
+
+	return t, nil
+}
+
+// NISCode is the code of a municipality, as assigned by Statbel. The feed sends it as a number, or as null if the municipality is unknown.
+type NISCode string
+
+// UnmarshalJSON unmarshals a NISCode from the API response.
+func (n *NISCode) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = ""
+		return nil
+	}
+	*n = NISCode(strings.Trim(string(b), `"`))
+	return nil
+}
+
+// CaseCount is the number of cases reported for a municipality. To protect privacy, Sciensano reports low counts as "<5".
+// Those are counted as zero.
+type CaseCount int
+
+// UnmarshalJSON unmarshals a CaseCount from the API response.
+func (c *CaseCount) UnmarshalJSON(b []byte) error {
+	value := strings.Trim(string(b), `"`)
+	if value == "<5" {
+		*c = 0
+		return nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid case count: %s", string(b))
+	}
+	*c = CaseCount(count)
+	return nil
+}
@@ -0,0 +1,95 @@
+package sciensano_test
+
+import (
+	"encoding/json"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMunicipalityCase_Unmarshal(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr assert.ErrorAssertionFunc
+		want    sciensano.MunicipalityCase
+	}{
+		{
+			name:    "count",
+			input:   `{"NIS5":11001,"CASES":"12"}`,
+			wantErr: assert.NoError,
+			want:    sciensano.MunicipalityCase{NIS: "11001", Cases: 12},
+		},
+		{
+			name:    "masked count",
+			input:   `{"NIS5":"11001","CASES":"<5"}`,
+			wantErr: assert.NoError,
+			want:    sciensano.MunicipalityCase{NIS: "11001"},
+		},
+		{
+			name:    "unknown municipality",
+			input:   `{"NIS5":null,"CASES":7}`,
+			wantErr: assert.NoError,
+			want:    sciensano.MunicipalityCase{Cases: 7},
+		},
+		{
+			name:    "invalid count",
+			input:   `{"NIS5":11001,"CASES":"many"}`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var c sciensano.MunicipalityCase
+			err := json.Unmarshal([]byte(tt.input), &c)
+			tt.wantErr(t, err)
+			if err == nil {
+				assert.Equal(t, tt.want, c)
+			}
+		})
+	}
+}
+
+func TestMunicipalityCases_Summarize(t *testing.T) {
+	testCases := []struct {
+		summaryColumn sciensano.SummaryColumn
+		wantErr       assert.ErrorAssertionFunc
+		want          []string
+	}{
+		{
+			summaryColumn: sciensano.Total,
+			wantErr:       assert.NoError,
+			want:          []string{"Total"},
+		},
+		{
+			summaryColumn: sciensano.ByRegion,
+			wantErr:       assert.NoError,
+			want:          []string{"(unknown)", "Brussels", "Flanders", "Wallonia"},
+		},
+		{
+			summaryColumn: sciensano.ByMunicipality,
+			wantErr:       assert.NoError,
+			want:          []string{"(unknown)", "11001", "11002", "21004", "21009", "44021", "62063", "71022", "92094"},
+		},
+		{
+			summaryColumn: sciensano.ByAgeGroup,
+			wantErr:       assert.Error,
+		},
+	}
+
+	cases := testutil.MunicipalityCases()
+	for _, tt := range testCases {
+		t.Run(tt.summaryColumn.String(), func(t *testing.T) {
+			table, err := cases.Summarize(tt.summaryColumn)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			require.NotZero(t, table.Size())
+			assert.Equal(t, tt.want, table.GetColumns())
+		})
+	}
+}
@@ -14,6 +14,8 @@ const (
 	ByManufacturer
 	ByVaccinationType
 	ByCategory
+	ByMunicipality
+	ByGender
 )
 
 var SummaryColumnNames map[string]SummaryColumn
@@ -21,7 +23,7 @@ var SummaryColumnNames map[string]SummaryColumn
 func init() {
 	SummaryColumnNames = make(map[string]SummaryColumn)
 
-	for i := range ByCategory + 1 {
+	for i := range ByGender + 1 {
 		SummaryColumnNames[i.String()] = i
 	}
 }
@@ -42,6 +44,10 @@ func (s SummaryColumn) String() string {
 		return "ByVaccinationType"
 	case ByCategory:
 		return "ByCategory"
+	case ByMunicipality:
+		return "ByMunicipality"
+	case ByGender:
+		return "ByGender"
 	}
 
 	panic(fmt.Sprintf("unknown summary column: %d", int(s)))
@@ -0,0 +1,180 @@
+package sciensano
+
+import (
+	"fmt"
+	"github.com/clambin/go-common/set"
+)
+
+// Tag is an attribute of a record that can be used to filter records.
+type Tag int
+
+const (
+	RegionTag Tag = iota
+	ProvinceTag
+	AgeGroupTag
+	ManufacturerTag
+	DoseTag
+)
+
+var TagNames map[string]Tag
+
+func init() {
+	TagNames = make(map[string]Tag)
+
+	for i := range DoseTag + 1 {
+		TagNames[i.String()] = i
+	}
+}
+
+func (t Tag) String() string {
+	switch t {
+	case RegionTag:
+		return "region"
+	case ProvinceTag:
+		return "province"
+	case AgeGroupTag:
+		return "ageGroup"
+	case ManufacturerTag:
+		return "manufacturer"
+	case DoseTag:
+		return "dose"
+	}
+
+	panic(fmt.Sprintf("unknown tag: %d", int(t)))
+}
+
+// Tagged is implemented by records that can be filtered by tag. GetTag returns the record's value for the tag,
+// and false if the record doesn't have the tag.
+type Tagged interface {
+	GetTag(tag Tag) (string, bool)
+}
+
+// Condition selects the records whose value for Tag equals Value or, if Negate is set, differs from Value.
+type Condition struct {
+	Tag    Tag
+	Value  string
+	Negate bool
+}
+
+// Filter selects the records that match all of its conditions. Conditions on a tag that a record doesn't have are ignored.
+// Use ValidateFilter to reject filters that use tags the records don't have.
+type Filter []Condition
+
+// ValidateFilter returns an error if the filter has a condition on a tag that records of type E don't have.
+func ValidateFilter[E Tagged](filter Filter) error {
+	var record E
+	for _, condition := range filter {
+		if _, ok := record.GetTag(condition.Tag); !ok {
+			return fmt.Errorf("filter not supported for tag %s", condition.Tag)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the record matches all conditions of the filter.
+func (f Filter) Matches(record Tagged) bool {
+	for _, condition := range f {
+		value, ok := record.GetTag(condition.Tag)
+		if ok && (value == condition.Value) == condition.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterRecords returns the records that match the filter.
+func FilterRecords[S ~[]E, E Tagged](records S, filter Filter) S {
+	if len(filter) == 0 {
+		return records
+	}
+	filtered := make(S, 0, len(records))
+	for _, record := range records {
+		if filter.Matches(record) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// TagValues returns the sorted, unique values of the tag in the records.
+func TagValues[S ~[]E, E Tagged](records S, tag Tag) []string {
+	values := set.Create[string]()
+	for _, record := range records {
+		if value, ok := record.GetTag(tag); ok && value != "" {
+			values.Add(value)
+		}
+	}
+	return values.ListOrdered()
+}
+
+func (c Case) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return c.Region, true
+	case ProvinceTag:
+		return c.Province, true
+	case AgeGroupTag:
+		return c.AgeGroup, true
+	default:
+		return "", false
+	}
+}
+
+func (h Hospitalisation) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return h.Region, true
+	case ProvinceTag:
+		return h.Province, true
+	default:
+		return "", false
+	}
+}
+
+func (m Mortality) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return m.Region, true
+	case AgeGroupTag:
+		return m.AgeGroup, true
+	default:
+		return "", false
+	}
+}
+
+func (r TestResult) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return r.Region, true
+	case ProvinceTag:
+		return r.Province, true
+	default:
+		return "", false
+	}
+}
+
+func (v Vaccination) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return v.Region, true
+	case AgeGroupTag:
+		return v.AgeGroup, true
+	case ManufacturerTag:
+		return v.Manufacturer, true
+	case DoseTag:
+		return v.Dose.String(), true
+	default:
+		return "", false
+	}
+}
+
+func (m MunicipalityCase) GetTag(tag Tag) (string, bool) {
+	switch tag {
+	case RegionTag:
+		return m.Region, true
+	case ProvinceTag:
+		return m.Province, true
+	default:
+		return "", false
+	}
+}
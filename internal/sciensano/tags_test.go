@@ -0,0 +1,87 @@
+package sciensano_test
+
+import (
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTag(t *testing.T) {
+	for name, tag := range sciensano.TagNames {
+		assert.Equal(t, name, tag.String())
+	}
+	assert.Panics(t, func() { _ = sciensano.Tag(-1).String() })
+}
+
+func TestFilterRecords(t *testing.T) {
+	day := sciensano.TimeStamp{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	cases := sciensano.Cases{
+		{TimeStamp: day, Region: "Flanders", Province: "Antwerpen", AgeGroup: "0-9", Cases: 1},
+		{TimeStamp: day, Region: "Flanders", Province: "Limburg", AgeGroup: "10-19", Cases: 2},
+		{TimeStamp: day, Region: "Wallonia", Province: "Namur", AgeGroup: "0-9", Cases: 4},
+	}
+
+	testCases := []struct {
+		name   string
+		filter sciensano.Filter
+		want   sciensano.Cases
+	}{
+		{
+			name: "no filter",
+			want: cases,
+		},
+		{
+			name:   "equal",
+			filter: sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}},
+			want:   cases[:2],
+		},
+		{
+			name:   "not equal",
+			filter: sciensano.Filter{{Tag: sciensano.AgeGroupTag, Value: "0-9", Negate: true}},
+			want:   cases[1:2],
+		},
+		{
+			name:   "combined",
+			filter: sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}, {Tag: sciensano.AgeGroupTag, Value: "0-9"}},
+			want:   cases[:1],
+		},
+		{
+			name:   "no match",
+			filter: sciensano.Filter{{Tag: sciensano.ProvinceTag, Value: "Liège"}},
+			want:   sciensano.Cases{},
+		},
+		{
+			name:   "tag not in records",
+			filter: sciensano.Filter{{Tag: sciensano.ManufacturerTag, Value: "Moderna"}},
+			want:   cases,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sciensano.FilterRecords(cases, tt.filter))
+		})
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	assert.NoError(t, sciensano.ValidateFilter[sciensano.Case](nil))
+	assert.NoError(t, sciensano.ValidateFilter[sciensano.Case](sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}}))
+	assert.Error(t, sciensano.ValidateFilter[sciensano.Case](sciensano.Filter{{Tag: sciensano.ManufacturerTag, Value: "Moderna"}}))
+	assert.NoError(t, sciensano.ValidateFilter[sciensano.Vaccination](sciensano.Filter{{Tag: sciensano.ManufacturerTag, Value: "Moderna"}}))
+}
+
+func TestTagValues(t *testing.T) {
+	vaccinations := sciensano.Vaccinations{
+		{Region: "Wallonia", AgeGroup: "85+", Manufacturer: "Pfizer-BioNTech", Dose: sciensano.Full},
+		{Region: "Flanders", AgeGroup: "45-54", Manufacturer: "Moderna", Dose: sciensano.Partial},
+		{Region: "Flanders", Manufacturer: "Moderna", Dose: sciensano.Booster},
+	}
+
+	assert.Equal(t, []string{"Flanders", "Wallonia"}, sciensano.TagValues(vaccinations, sciensano.RegionTag))
+	assert.Equal(t, []string{"45-54", "85+"}, sciensano.TagValues(vaccinations, sciensano.AgeGroupTag))
+	assert.Equal(t, []string{"Moderna", "Pfizer-BioNTech"}, sciensano.TagValues(vaccinations, sciensano.ManufacturerTag))
+	assert.Equal(t, []string{"Booster", "Full", "Partial"}, sciensano.TagValues(vaccinations, sciensano.DoseTag))
+	assert.Empty(t, sciensano.TagValues(vaccinations, sciensano.ProvinceTag))
+}
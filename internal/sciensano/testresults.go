@@ -25,6 +25,10 @@ func (r TestResults) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulato
 		return r.Categorize(), nil
 	}
 
+	return r.summarize(summaryColumn, func(testResult TestResult) int { return testResult.Total })
+}
+
+func (r TestResults) summarize(summaryColumn SummaryColumn, getValue func(TestResult) int) (*tabulator.Tabulator, error) {
 	t := tabulator.New()
 
 	columnNames := set.Create[string]()
@@ -48,12 +52,53 @@ func (r TestResults) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulato
 			columnNames.Add(columnName)
 		}
 
-		t.Add(testResult.TimeStamp.Time, columnName, float64(testResult.Total))
+		t.Add(testResult.TimeStamp.Time, columnName, float64(getValue(testResult)))
 	}
 
 	return t, nil
 }
 
+// PositivityRate returns the share of positive tests over a rolling window of days days, up to and including each day.
+// Days without tests don't count towards the rate. If there were no tests during the whole window, the rate is zero.
+func (r TestResults) PositivityRate(summaryColumn SummaryColumn, days int) (*tabulator.Tabulator, error) {
+	if summaryColumn == ByCategory {
+		return nil, fmt.Errorf("testResults: invalid summary column: %s", summaryColumn.String())
+	}
+	total, err := r.Summarize(summaryColumn)
+	if err != nil {
+		return nil, err
+	}
+	positive, err := r.summarize(summaryColumn, func(testResult TestResult) int { return testResult.Positive })
+	if err != nil {
+		return nil, err
+	}
+	if days < 1 {
+		days = 1
+	}
+
+	timestamps := total.GetTimestamps()
+	columns := total.GetColumns()
+	rates := tabulator.New(columns...)
+	for _, column := range columns {
+		totalValues, _ := total.GetValues(column)
+		positiveValues, _ := positive.GetValues(column)
+		for index, timestamp := range timestamps {
+			from := timestamp.AddDate(0, 0, -days)
+			var totalTests, positiveTests float64
+			for j := index; j >= 0 && timestamps[j].After(from); j-- {
+				totalTests += totalValues[j]
+				positiveTests += positiveValues[j]
+			}
+			var rate float64
+			if totalTests > 0 {
+				rate = positiveTests / totalTests
+			}
+			rates.Set(timestamp, column, rate)
+		}
+	}
+	return rates, nil
+}
+
 func (r TestResults) Categorize() *tabulator.Tabulator {
 	t := tabulator.New("positive", "total")
 
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestTestResults_Unmarshal(t *testing.T) {
@@ -82,3 +83,69 @@ func TestTestResults_Summarize(t *testing.T) {
 		})
 	}
 }
+
+func TestTestResults_PositivityRate(t *testing.T) {
+	day := func(d int) sciensano.TimeStamp {
+		return sciensano.TimeStamp{Time: time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)}
+	}
+	testResults := sciensano.TestResults{
+		{TimeStamp: day(1), Region: "Flanders", Total: 100, Positive: 10},
+		{TimeStamp: day(1), Region: "Wallonia", Total: 50, Positive: 20},
+		{TimeStamp: day(2), Region: "Flanders", Total: 0, Positive: 0},
+		{TimeStamp: day(2), Region: "Wallonia", Total: 0, Positive: 0},
+		{TimeStamp: day(3), Region: "Flanders", Total: 100, Positive: 30},
+		{TimeStamp: day(3), Region: "Wallonia", Total: 0, Positive: 0},
+		{TimeStamp: day(4), Region: "Flanders", Total: 100, Positive: 30},
+		{TimeStamp: day(4), Region: "Wallonia", Total: 0, Positive: 0},
+	}
+
+	testCases := []struct {
+		name    string
+		mode    sciensano.SummaryColumn
+		days    int
+		want    map[string][]float64
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "daily",
+			mode:    sciensano.ByRegion,
+			days:    1,
+			want:    map[string][]float64{"Flanders": {0.1, 0, 0.3, 0.3}, "Wallonia": {0.4, 0, 0, 0}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "rolling",
+			mode:    sciensano.ByRegion,
+			days:    3,
+			want:    map[string][]float64{"Flanders": {0.1, 0.1, 0.2, 0.3}, "Wallonia": {0.4, 0.4, 0.4, 0}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "total",
+			mode:    sciensano.Total,
+			days:    2,
+			want:    map[string][]float64{"Total": {0.2, 0.2, 0.3, 0.3}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid",
+			mode:    sciensano.ByCategory,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := testResults.PositivityRate(tt.mode, tt.days)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			for column, want := range tt.want {
+				values, ok := rates.GetValues(column)
+				require.True(t, ok)
+				assert.InDeltaSlice(t, want, values, 0.0001)
+			}
+		})
+	}
+}
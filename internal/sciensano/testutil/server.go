@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,11 +12,9 @@ func NewTestServer() *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(sciensanoHandler))
 }
 
+var lastModified = time.Now()
+
 func sciensanoHandler(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodHead {
-		w.Header().Set("Last-Modified", time.Now().Format(time.RFC1123))
-		return
-	}
 	var data any
 	switch req.URL.Path {
 	case "/Data/COVID19BE_CASES_AGESEX.json":
@@ -28,10 +27,15 @@ func sciensanoHandler(w http.ResponseWriter, req *http.Request) {
 		data = TestResults()
 	case "/Data/COVID19BE_VACC.json":
 		data = Vaccinations()
+	case "/Data/COVID19BE_CASES_MUNI.json":
+		data = MunicipalityCases()
 	default:
 		panic(req.URL.Path)
 	}
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	http.ServeContent(w, req, req.URL.Path, lastModified, bytes.NewReader(body.Bytes()))
 }
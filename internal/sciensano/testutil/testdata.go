@@ -31,6 +31,10 @@ func Vaccinations() sciensano.Vaccinations {
 	return getTestData[sciensano.Vaccinations]("vaccinations.json")
 }
 
+func MunicipalityCases() sciensano.MunicipalityCases {
+	return getTestData[sciensano.MunicipalityCases]("municipalityCases.json")
+}
+
 func getTestData[T any](filename string) T {
 	f, err := testFiles.Open(path.Join("testdata", filename))
 	if err != nil {
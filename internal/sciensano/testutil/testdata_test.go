@@ -32,6 +32,7 @@ func TestReferenceData(t *testing.T) {
 	assert.NotEmpty(t, testutil.Mortalities())
 	assert.NotEmpty(t, testutil.Hospitalisations())
 	assert.NotEmpty(t, testutil.Vaccinations())
+	assert.NotEmpty(t, testutil.MunicipalityCases())
 }
 
 func updateReferenceFiles() {
@@ -40,6 +41,7 @@ func updateReferenceFiles() {
 	updateReferenceFile[*sciensano.Mortality]("https://epistat.sciensano.be/Data/COVID19BE_MORT.json", "mortalities.json")
 	updateReferenceFile[*sciensano.TestResult]("https://epistat.sciensano.be/Data/COVID19BE_tests.json", "testResults.json")
 	updateReferenceFile[*sciensano.Vaccination]("https://epistat.sciensano.be/Data/COVID19BE_VACC.json", "vaccinations.json")
+	updateReferenceFile[*sciensano.MunicipalityCase]("https://epistat.sciensano.be/Data/COVID19BE_CASES_MUNI.json", "municipalityCases.json")
 }
 
 func updateReferenceFile[T any](source, filename string) {
@@ -100,6 +102,8 @@ func getRecordTimestamp(record any) time.Time {
 		return r.TimeStamp.Time
 	case *sciensano.Vaccination:
 		return r.TimeStamp.Time
+	case *sciensano.MunicipalityCase:
+		return r.TimeStamp.Time
 	}
 	panic(fmt.Errorf("invalid type: %v", record))
 }
@@ -2,6 +2,7 @@ package sciensano
 
 import (
 	"fmt"
+	"path"
 )
 
 const baseURL = "https://epistat.sciensano.be"
@@ -14,31 +15,126 @@ const (
 	MortalitiesEndpoint
 	TestResultsEndpoint
 	VaccinationsEndpoint
+	MunicipalityCasesEndpoint
 )
 
 var routes = map[Endpoint]string{
-	CasesEndpoint:            "/Data/COVID19BE_CASES_AGESEX.json",
-	HospitalisationsEndpoint: "/Data/COVID19BE_HOSP.json",
-	MortalitiesEndpoint:      "/Data/COVID19BE_MORT.json",
-	TestResultsEndpoint:      "/Data/COVID19BE_tests.json",
-	VaccinationsEndpoint:     "/Data/COVID19BE_VACC.json",
+	CasesEndpoint:             "/Data/COVID19BE_CASES_AGESEX",
+	HospitalisationsEndpoint:  "/Data/COVID19BE_HOSP",
+	MortalitiesEndpoint:       "/Data/COVID19BE_MORT",
+	TestResultsEndpoint:       "/Data/COVID19BE_tests",
+	VaccinationsEndpoint:      "/Data/COVID19BE_VACC",
+	MunicipalityCasesEndpoint: "/Data/COVID19BE_CASES_MUNI",
+}
+
+var endpointStrings = map[Endpoint]string{
+	CasesEndpoint:             "cases",
+	HospitalisationsEndpoint:  "hospitalisations",
+	MortalitiesEndpoint:       "mortalities",
+	TestResultsEndpoint:       "testResults",
+	VaccinationsEndpoint:      "vaccinations",
+	MunicipalityCasesEndpoint: "municipalityCases",
+}
+
+var EndpointNames map[string]Endpoint
+
+func init() {
+	EndpointNames = make(map[string]Endpoint)
+	for endpoint, name := range endpointStrings {
+		EndpointNames[name] = endpoint
+	}
+}
+
+func (e Endpoint) String() string {
+	value, ok := endpointStrings[e]
+	if !ok {
+		value = "(unknown)"
+	}
+	return value
+}
+
+// Format is the file format in which an endpoint's data is published.
+type Format int
+
+const (
+	JSON Format = iota
+	CSV
+)
+
+var formatStrings = map[Format]string{
+	JSON: "json",
+	CSV:  "csv",
+}
+
+var FormatNames map[string]Format
+
+func init() {
+	FormatNames = make(map[string]Format)
+	for format, name := range formatStrings {
+		FormatNames[name] = format
+	}
+}
+
+func (f Format) String() string {
+	value, ok := formatStrings[f]
+	if !ok {
+		value = "(unknown)"
+	}
+	return value
 }
 
 func MustGetURL(base string, endpoint Endpoint) string {
-	url, err := GetURL(base, endpoint)
+	return MustGetURLForFormat(base, endpoint, JSON)
+}
+
+func GetURL(base string, endpoint Endpoint) (string, error) {
+	return GetURLForFormat(base, endpoint, JSON)
+}
+
+func MustGetURLForFormat(base string, endpoint Endpoint, format Format) string {
+	url, err := GetURLForFormat(base, endpoint, format)
 	if err != nil {
 		panic(err)
 	}
 	return url
 }
 
-func GetURL(base string, endpoint Endpoint) (string, error) {
+func GetURLForFormat(base string, endpoint Endpoint, format Format) (string, error) {
 	if base == "" {
 		base = baseURL
 	}
+	route, err := getRoute(endpoint, format)
+	if err != nil {
+		return "", err
+	}
+	return base + route, nil
+}
+
+func MustGetFilename(endpoint Endpoint, format Format) string {
+	filename, err := GetFilename(endpoint, format)
+	if err != nil {
+		panic(err)
+	}
+	return filename
+}
+
+// GetFilename returns the name of the file in which Sciensano publishes the endpoint's data, e.g. COVID19BE_VACC.json.
+func GetFilename(endpoint Endpoint, format Format) (string, error) {
+	route, err := getRoute(endpoint, format)
+	if err != nil {
+		return "", err
+	}
+	return path.Base(route), nil
+}
+
+func getRoute(endpoint Endpoint, format Format) (string, error) {
 	route, ok := routes[endpoint]
 	if !ok {
 		return "", fmt.Errorf("invalid endpoint %d", endpoint)
 	}
-	return base + route, nil
+	extension, ok := formatStrings[format]
+	if !ok {
+		return "", fmt.Errorf("invalid format %d", format)
+	}
+	return route + "." + extension, nil
 }
@@ -3,6 +3,7 @@ package sciensano_test
 import (
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -17,3 +18,28 @@ func TestGetURL(t *testing.T) {
 		_ = sciensano.MustGetURL("", -1)
 	})
 }
+
+func TestGetURLForFormat(t *testing.T) {
+	url, err := sciensano.GetURLForFormat("", sciensano.VaccinationsEndpoint, sciensano.CSV)
+	require.NoError(t, err)
+	assert.Equal(t, "https://epistat.sciensano.be/Data/COVID19BE_VACC.csv", url)
+
+	_, err = sciensano.GetURLForFormat("", sciensano.VaccinationsEndpoint, -1)
+	assert.Error(t, err)
+}
+
+func TestEndpoint_String(t *testing.T) {
+	for name, endpoint := range sciensano.EndpointNames {
+		assert.Equal(t, name, endpoint.String())
+	}
+	assert.Equal(t, "(unknown)", sciensano.Endpoint(-1).String())
+}
+
+func TestGetFilename(t *testing.T) {
+	filename, err := sciensano.GetFilename(sciensano.HospitalisationsEndpoint, sciensano.JSON)
+	require.NoError(t, err)
+	assert.Equal(t, "COVID19BE_HOSP.json", filename)
+
+	_, err = sciensano.GetFilename(-1, sciensano.JSON)
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/clambin/go-common/set"
 	"github.com/clambin/go-common/tabulator"
+	"iter"
+	"slices"
 )
 
 //easyjson:json
@@ -30,6 +32,8 @@ func (v Vaccination) GetSummaryColumnName(column SummaryColumn) (string, error)
 		columnName = v.Manufacturer
 	case ByVaccinationType:
 		columnName = v.Dose.String()
+	case ByGender:
+		columnName = v.Gender
 	default:
 		return "nil", fmt.Errorf("invalid summary column: %s", column.String())
 	}
@@ -40,14 +44,19 @@ func (v Vaccination) GetSummaryColumnName(column SummaryColumn) (string, error)
 type Vaccinations []Vaccination
 
 func VaccinationsValidSummaryModes() set.Set[SummaryColumn] {
-	return set.Create(Total, ByRegion, ByAgeGroup, ByManufacturer, ByVaccinationType)
+	return set.Create(Total, ByRegion, ByAgeGroup, ByManufacturer, ByVaccinationType, ByGender)
 }
 
 func (v Vaccinations) Summarize(summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
+	return SummarizeVaccinations(slices.Values(v), summaryColumn)
+}
+
+// SummarizeVaccinations summarizes a stream of vaccinations, without needing to hold all records in memory.
+func SummarizeVaccinations(vaccinations iter.Seq[Vaccination], summaryColumn SummaryColumn) (*tabulator.Tabulator, error) {
 	t := tabulator.New()
 
 	columnNames := set.Create[string]()
-	for _, vaccination := range v {
+	for vaccination := range vaccinations {
 		columnName, err := vaccination.GetSummaryColumnName(summaryColumn)
 		if err != nil {
 			return nil, fmt.Errorf("summary: %w", err)
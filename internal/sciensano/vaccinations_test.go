@@ -1,6 +1,7 @@
 package sciensano_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -77,6 +79,21 @@ func BenchmarkVaccinations_Unmarshal_Easyjson(b *testing.B) {
 	}
 }
 
+// BenchmarkVaccinations_Unmarshal_Decoder decodes the feed one record at a time, as sciensano.Fetcher does.
+// Compare with BenchmarkVaccinations_Unmarshal_Easyjson, which needs the full response in memory.
+func BenchmarkVaccinations_Unmarshal_Decoder(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("testutil", "testdata", "vaccinations.json"))
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		d := sciensano.NewDecoder[sciensano.Vaccination](bytes.NewReader(content))
+		if _ = slices.Collect(d.All()); d.Err() != nil {
+			b.Fatal(d.Err())
+		}
+	}
+}
+
 func TestVaccinations_Summarize(t *testing.T) {
 	testCases := []struct {
 		summaryColumn sciensano.SummaryColumn
@@ -127,6 +144,26 @@ func TestVaccinations_Summarize(t *testing.T) {
 	}
 }
 
+func TestVaccination_GetSummaryColumnName(t *testing.T) {
+	v := sciensano.Vaccination{Manufacturer: "Moderna", Region: "Flanders", AgeGroup: "85+", Gender: "F", Dose: sciensano.Booster}
+
+	for column, want := range map[sciensano.SummaryColumn]string{
+		sciensano.Total:             "Total",
+		sciensano.ByRegion:          "Flanders",
+		sciensano.ByAgeGroup:        "85+",
+		sciensano.ByManufacturer:    "Moderna",
+		sciensano.ByVaccinationType: "Booster",
+		sciensano.ByGender:          "F",
+	} {
+		name, err := v.GetSummaryColumnName(column)
+		require.NoError(t, err)
+		assert.Equal(t, want, name)
+	}
+
+	_, err := v.GetSummaryColumnName(sciensano.ByProvince)
+	assert.Error(t, err)
+}
+
 func BenchmarkVaccinations_Summarize_Total(b *testing.B) {
 	vaccinations := testutil.Vaccinations()
 	b.ResetTimer()
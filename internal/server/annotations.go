@@ -0,0 +1,179 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+type UpdateReporter interface {
+	GetUpdates(from, to time.Time) []datasource.Update
+}
+
+// Event is a user-maintained annotation, e.g. a lockdown or a change in testing policy.
+type Event struct {
+	// Title of the event
+	Title string `yaml:"title"`
+	// Text describes the event in more detail
+	Text string `yaml:"text"`
+	// Start of the event
+	Start time.Time `yaml:"start"`
+	// End of the event. If set, the event is shown as a region
+	End time.Time `yaml:"end"`
+	// Tags of the event, so dashboards can select the events to show
+	Tags []string `yaml:"tags"`
+}
+
+// LoadEvents reads the events in the file at path. The file holds a YAML list of events.
+func LoadEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("events: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	events, err := ParseEvents(f)
+	if err != nil {
+		return nil, fmt.Errorf("events: %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// ParseEvents reads and validates a YAML list of events.
+func ParseEvents(r io.Reader) ([]Event, error) {
+	var events []Event
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&events); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	var errs []error
+	for index, event := range events {
+		switch {
+		case event.Title == "":
+			errs = append(errs, fmt.Errorf("event %d: missing title", index+1))
+		case event.Start.IsZero():
+			errs = append(errs, fmt.Errorf("event %q: missing start", event.Title))
+		case !event.End.IsZero() && event.End.Before(event.Start):
+			errs = append(errs, fmt.Errorf("event %q: end before start", event.Title))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+const (
+	updateTag = "update"
+	eventTag  = "event"
+)
+
+type annotationRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Annotation json.RawMessage `json:"annotation"`
+}
+
+type annotation struct {
+	Annotation json.RawMessage `json:"annotation,omitempty"`
+	Time       int64           `json:"time"`
+	TimeEnd    int64           `json:"timeEnd,omitempty"`
+	IsRegion   bool            `json:"isRegion,omitempty"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text,omitempty"`
+	Tags       []string        `json:"tags"`
+}
+
+// Annotations returns the Grafana annotations for the requested time range: one for each time a datasource published
+// new data (tagged "update" and the datasource's name) and the user-maintained events (tagged "event" and the event's tags).
+// The annotation's query optionally holds a comma-separated list of tags: only annotations with one of those tags are returned.
+func (s *Server) Annotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request annotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var query struct {
+		Query string `json:"query"`
+	}
+	if len(request.Annotation) > 0 {
+		if err := json.Unmarshal(request.Annotation, &query); err != nil {
+			http.Error(w, "invalid annotation: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	annotations := make([]annotation, 0)
+	for _, a := range s.getAnnotations(request.Range.From, request.Range.To) {
+		if matchesTags(a.Tags, query.Query) {
+			a.Annotation = request.Annotation
+			annotations = append(annotations, a)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(annotations)
+}
+
+func (s *Server) getAnnotations(from, to time.Time) []annotation {
+	var annotations []annotation
+	if s.Updates != nil {
+		for _, update := range s.Updates.GetUpdates(from, to) {
+			annotations = append(annotations, annotation{
+				Time:  update.LastModified.UnixMilli(),
+				Title: update.Name + " updated",
+				Text:  fmt.Sprintf("%d records", update.Records),
+				Tags:  []string{updateTag, update.Name},
+			})
+		}
+	}
+	for _, event := range s.Events {
+		end := event.End
+		if end.IsZero() {
+			end = event.Start
+		}
+		if event.Start.After(to) || end.Before(from) {
+			continue
+		}
+		a := annotation{
+			Time:  event.Start.UnixMilli(),
+			Title: event.Title,
+			Text:  event.Text,
+			Tags:  append([]string{eventTag}, event.Tags...),
+		}
+		if !event.End.IsZero() {
+			a.TimeEnd = event.End.UnixMilli()
+			a.IsRegion = true
+		}
+		annotations = append(annotations, a)
+	}
+	slices.SortStableFunc(annotations, func(a, b annotation) int { return cmp.Compare(a.Time, b.Time) })
+	return annotations
+}
+
+func matchesTags(tags []string, query string) bool {
+	if strings.TrimSpace(query) == "" {
+		return true
+	}
+	for _, tag := range strings.Split(query, ",") {
+		if slices.Contains(tags, strings.TrimSpace(tag)) {
+			return true
+		}
+	}
+	return false
+}
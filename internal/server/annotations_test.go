@@ -0,0 +1,165 @@
+package server
+
+import (
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/server/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_Annotations(t *testing.T) {
+	var updates datasource.UpdateLog
+	updates.Record(datasource.Update{Name: "cases", LastModified: time.Date(2021, time.March, 2, 8, 0, 0, 0, time.UTC), Records: 1000})
+	updates.Record(datasource.Update{Name: "vaccinations", LastModified: time.Date(2021, time.April, 1, 8, 0, 0, 0, time.UTC), Records: 500})
+
+	s := New(mocks.NewReportsStore(t), config.Default(), nil, slog.Default())
+	s.Updates = &updates
+	s.Events = []Event{
+		{Title: "Lockdown", Text: "Non-essential shops closed", Start: time.Date(2021, time.March, 27, 0, 0, 0, 0, time.UTC), End: time.Date(2021, time.April, 26, 0, 0, 0, 0, time.UTC), Tags: []string{"lockdown"}},
+		{Title: "Testing policy", Start: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"testing"}},
+		{Title: "Vaccination phase 1a", Start: time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "all",
+			method:   http.MethodPost,
+			body:     `{"range":{"from":"2021-03-01T00:00:00.000Z","to":"2021-03-31T00:00:00.000Z"},"annotation":{"name":"all","query":""}}`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"annotation":{"name":"all","query":""},"time":1614556800000,"title":"Testing policy","tags":["event","testing"]},{"annotation":{"name":"all","query":""},"time":1614672000000,"title":"cases updated","text":"1000 records","tags":["update","cases"]},{"annotation":{"name":"all","query":""},"time":1616803200000,"timeEnd":1619395200000,"isRegion":true,"title":"Lockdown","text":"Non-essential shops closed","tags":["event","lockdown"]}]
+`,
+		},
+		{
+			name:     "by tag",
+			method:   http.MethodPost,
+			body:     `{"range":{"from":"2021-03-01T00:00:00Z","to":"2021-04-30T00:00:00Z"},"annotation":{"query":"update, lockdown"}}`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"annotation":{"query":"update, lockdown"},"time":1614672000000,"title":"cases updated","text":"1000 records","tags":["update","cases"]},{"annotation":{"query":"update, lockdown"},"time":1616803200000,"timeEnd":1619395200000,"isRegion":true,"title":"Lockdown","text":"Non-essential shops closed","tags":["event","lockdown"]},{"annotation":{"query":"update, lockdown"},"time":1617264000000,"title":"vaccinations updated","text":"500 records","tags":["update","vaccinations"]}]
+`,
+		},
+		{
+			name:     "none",
+			method:   http.MethodPost,
+			body:     `{"range":{"from":"2022-01-01T00:00:00Z","to":"2022-02-01T00:00:00Z"}}`,
+			wantCode: http.StatusOK,
+			wantBody: "[]\n",
+		},
+		{
+			name:     "invalid request",
+			method:   http.MethodPost,
+			body:     `{"range":`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid method",
+			method:   http.MethodGet,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/annotations", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			s.JSONServer.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr assert.ErrorAssertionFunc
+		want    []Event
+	}{
+		{
+			name: "valid",
+			input: `
+- title: Lockdown
+  text: Non-essential shops closed
+  start: 2021-03-27
+  end: 2021-04-26
+  tags: [lockdown]
+- title: Testing policy
+  start: 2021-03-01T12:00:00Z
+`,
+			wantErr: assert.NoError,
+			want: []Event{
+				{Title: "Lockdown", Text: "Non-essential shops closed", Start: time.Date(2021, time.March, 27, 0, 0, 0, 0, time.UTC), End: time.Date(2021, time.April, 26, 0, 0, 0, 0, time.UTC), Tags: []string{"lockdown"}},
+				{Title: "Testing policy", Start: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:    "empty",
+			input:   ``,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid yaml",
+			input:   `- title: [`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown field",
+			input:   "- title: Lockdown\n  date: 2021-03-27\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing title",
+			input:   "- start: 2021-03-27\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing start",
+			input:   "- title: Lockdown\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "end before start",
+			input:   "- title: Lockdown\n  start: 2021-03-27\n  end: 2021-03-01\n",
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := ParseEvents(strings.NewReader(tt.input))
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, events)
+		})
+	}
+}
+
+func TestLoadEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.yaml")
+	_, err := LoadEvents(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("- title: Lockdown\n  start: 2021-03-27\n"), 0644))
+	events, err := LoadEvents(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{Title: "Lockdown", Start: time.Date(2021, time.March, 27, 0, 0, 0, 0, time.UTC)}}, events)
+}
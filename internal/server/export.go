@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/clambin/go-common/tabulator"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ListReports returns the keys of all stored reports.
+func (s *Server) ListReports(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.reports.Keys())
+}
+
+// ExportReport returns the report with the requested key, for use outside of Grafana. The key is passed as a query
+// parameter, as are the optional from, to, accumulate ("yes" or "no"), smoothing and format parameters.
+// from and to are either a date (2006-01-02) or an RFC3339 timestamp. format is json (the default), csv or ndjson.
+func (s *Server) ExportReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	key := query.Get("key")
+	if key == "" {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+	from, err := parseTime(query.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(query.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	accumulate := query.Get("accumulate")
+	if accumulate == "" {
+		accumulate = "no"
+	}
+	options, err := parseQueryOptions(accumulate, query.Get("smoothing"), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, ok := exportFormats[query.Get("format")]
+	if !ok {
+		http.Error(w, "invalid format: "+query.Get("format"), http.StatusBadRequest)
+		return
+	}
+
+	records, err := s.reports.Get(key)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("fetch %s failed: %s", key, err), http.StatusNotFound)
+		return
+	}
+	records = transform(records, options, from, to)
+
+	w.Header().Set("Content-Type", format.contentType)
+	if err = format.write(w, records); err != nil {
+		// part of the response may already have been sent, so it's too late to report an error to the client
+		s.logger.Warn("failed to write report", "key", key, "err", err)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+type exportFormat struct {
+	contentType string
+	write       func(io.Writer, *tabulator.Tabulator) error
+}
+
+var exportFormats = map[string]exportFormat{
+	"":       {contentType: "application/json", write: writeJSON},
+	"json":   {contentType: "application/json", write: writeJSON},
+	"csv":    {contentType: "text/csv", write: writeCSV},
+	"ndjson": {contentType: "application/x-ndjson", write: writeNDJSON},
+}
+
+// writeJSON writes the report as an array of rows. Each row is an object with the row's time and the value of each column.
+func writeJSON(w io.Writer, t *tabulator.Tabulator) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	err := writeRows(w, t, ",")
+	if err == nil {
+		_, err = io.WriteString(w, "]\n")
+	}
+	return err
+}
+
+// writeNDJSON writes the report as one JSON object per row. Each row has the row's time and the value of each column.
+func writeNDJSON(w io.Writer, t *tabulator.Tabulator) error {
+	err := writeRows(w, t, "\n")
+	if err == nil && len(t.GetTimestamps()) > 0 {
+		_, err = io.WriteString(w, "\n")
+	}
+	return err
+}
+
+func writeRows(w io.Writer, t *tabulator.Tabulator, separator string) error {
+	columns := t.GetColumns()
+	names := make([][]byte, len(columns))
+	values := make([][]float64, len(columns))
+	for index, column := range columns {
+		names[index], _ = json.Marshal(column)
+		values[index], _ = t.GetValues(column)
+	}
+
+	for row, timestamp := range t.GetTimestamps() {
+		// write the fields manually, so they appear in the same order as the report's columns
+		line := []byte(`{"time":"`)
+		line = timestamp.AppendFormat(line, time.RFC3339)
+		line = append(line, '"')
+		for index := range columns {
+			line = append(line, ',')
+			line = append(line, names[index]...)
+			line = append(line, ':')
+			line = appendNumber(line, values[index][row])
+		}
+		line = append(line, '}')
+		if row > 0 {
+			line = append([]byte(separator), line...)
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendNumber appends a value as a JSON number. JSON has no representation for NaN and infinity: those are written as null.
+func appendNumber(b []byte, value float64) []byte {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return append(b, "null"...)
+	}
+	return strconv.AppendFloat(b, value, 'f', -1, 64)
+}
+
+// writeCSV writes the report as CSV, with a header row naming the columns. NaN and infinity are written as empty cells,
+// as they are written as null in JSON.
+func writeCSV(w io.Writer, t *tabulator.Tabulator) error {
+	columns := t.GetColumns()
+	values := make([][]float64, len(columns))
+	for index, column := range columns {
+		values[index], _ = t.GetValues(column)
+	}
+
+	c := csv.NewWriter(w)
+	record := make([]string, 1+len(columns))
+	record[0] = "time"
+	copy(record[1:], columns)
+	if err := c.Write(record); err != nil {
+		return err
+	}
+	for row, timestamp := range t.GetTimestamps() {
+		record[0] = timestamp.Format(time.RFC3339)
+		for index := range columns {
+			record[index+1] = formatNumber(values[index][row])
+		}
+		if err := c.Write(record); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+func formatNumber(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
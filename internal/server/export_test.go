@@ -0,0 +1,200 @@
+package server
+
+import (
+	"errors"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/server/mocks"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_ListReports(t *testing.T) {
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Keys().Return([]string{"cases-Total", "cases-ByRegion"})
+	s := New(r, config.Default(), nil, slog.Default())
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/reports", nil)
+	w := httptest.NewRecorder()
+	s.JSONServer.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `["cases-Total","cases-ByRegion"]`+"\n", w.Body.String())
+}
+
+func TestServer_ExportReport(t *testing.T) {
+	report := tabulator.New("B", "A")
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		report.Set(day.AddDate(0, 0, i), "A", float64(i+1))
+		report.Set(day.AddDate(0, 0, i), "B", 0.5)
+	}
+
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Get("foo").Return(report, nil).Maybe()
+	r.EXPECT().Get("bar").Return(nil, errors.New("not found")).Maybe()
+	s := New(r, config.Default(), nil, slog.Default())
+
+	testCases := []struct {
+		name            string
+		query           string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "json",
+			query:           "key=foo",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `[{"time":"2024-03-01T00:00:00Z","A":1,"B":0.5},{"time":"2024-03-02T00:00:00Z","A":2,"B":0.5},{"time":"2024-03-03T00:00:00Z","A":3,"B":0.5}]
+`,
+		},
+		{
+			name:            "csv",
+			query:           "key=foo&format=csv",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody: `time,A,B
+2024-03-01T00:00:00Z,1,0.5
+2024-03-02T00:00:00Z,2,0.5
+2024-03-03T00:00:00Z,3,0.5
+`,
+		},
+		{
+			name:            "ndjson",
+			query:           "key=foo&format=ndjson&from=2024-03-02",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody: `{"time":"2024-03-02T00:00:00Z","A":2,"B":0.5}
+{"time":"2024-03-03T00:00:00Z","A":3,"B":0.5}
+`,
+		},
+		{
+			name:            "accumulated",
+			query:           "key=foo&format=csv&accumulate=yes&to=2024-03-02T00:00:00Z",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody: `time,A,B
+2024-03-01T00:00:00Z,1,0.5
+2024-03-02T00:00:00Z,3,1
+`,
+		},
+		{
+			name:            "empty",
+			query:           "key=foo&from=2025-01-01",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        "[]\n",
+		},
+		{
+			name:     "missing key",
+			query:    "",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid from",
+			query:    "key=foo&from=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid to",
+			query:    "key=foo&to=tomorrow",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid accumulate",
+			query:    "key=foo&accumulate=true",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid smoothing",
+			query:    "key=foo&smoothing=14d",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid format",
+			query:    "key=foo&format=xml",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown report",
+			query:    "key=bar",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/report?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			s.JSONServer.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestServer_ExportReport_Missing(t *testing.T) {
+	report := tabulator.New("A", "B", "C")
+	report.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "A", math.NaN())
+	report.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "B", math.Inf(1))
+	report.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "C", 1)
+
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Get("foo").Return(report, nil)
+	s := New(r, config.Default(), nil, slog.Default())
+
+	for format, want := range map[string]string{
+		"json":   `[{"time":"2024-03-01T00:00:00Z","A":null,"B":null,"C":1}]` + "\n",
+		"ndjson": `{"time":"2024-03-01T00:00:00Z","A":null,"B":null,"C":1}` + "\n",
+		"csv":    "time,A,B,C\n2024-03-01T00:00:00Z,,,1\n",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/report?key=foo&format="+format, nil)
+		w := httptest.NewRecorder()
+		s.JSONServer.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, format)
+		assert.Equal(t, want, w.Body.String(), format)
+	}
+}
+
+// failingResponseWriter fails to write the response body
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+	statusCodes []int
+}
+
+func (w *failingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCodes = append(w.statusCodes, statusCode)
+	w.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func (w *failingResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection closed")
+}
+
+func TestServer_ExportReport_WriteFailure(t *testing.T) {
+	report := tabulator.New("A")
+	report.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "A", 1)
+
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Get("foo").Return(report, nil)
+	s := New(r, config.Default(), nil, slog.Default())
+
+	// once the body is being written, the status can't be changed anymore
+	req, _ := http.NewRequest(http.MethodGet, "/api/report?key=foo", nil)
+	w := failingResponseWriter{ResponseRecorder: httptest.NewRecorder()}
+	s.ExportReport(&w, req)
+	assert.Empty(t, w.statusCodes)
+}
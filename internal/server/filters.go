@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/clambin/go-common/tabulator"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"io"
+	"net/http"
+)
+
+// RecordsSource gives access to the records underlying the reports, so queries can use ad-hoc filters and secondary breakdowns.
+type RecordsSource interface {
+	// TagValues returns the values of the tag in the current records.
+	TagValues(tag sciensano.Tag) []string
+	// Summarize summarizes the records of the datasource that match the filter, as determined by the breakdown.
+	Summarize(datasource string, breakdown sciensano.Breakdown, filter sciensano.Filter) (*tabulator.Tabulator, error)
+}
+
+// tagKeys are the tag keys offered for ad-hoc filters, in the order they're shown in Grafana.
+var tagKeys = []sciensano.Tag{sciensano.RegionTag, sciensano.ProvinceTag, sciensano.AgeGroupTag, sciensano.ManufacturerTag, sciensano.DoseTag}
+
+// TagKeys returns the keys that can be used in ad-hoc filters.
+func (s *Server) TagKeys(w http.ResponseWriter, _ *http.Request) {
+	type tagKey struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	keys := make([]tagKey, len(tagKeys))
+	for index, tag := range tagKeys {
+		keys[index] = tagKey{Type: "string", Text: tag.String()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(keys)
+}
+
+// TagValues returns the values of the requested tag key, as found in the current records.
+func (s *Server) TagValues(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tag, ok := sciensano.TagNames[request.Key]
+	if !ok {
+		http.Error(w, "invalid tag key: "+request.Key, http.StatusBadRequest)
+		return
+	}
+
+	type tagValue struct {
+		Text string `json:"text"`
+	}
+	values := make([]tagValue, 0)
+	if s.Records != nil {
+		for _, value := range s.Records.TagValues(tag) {
+			values = append(values, tagValue{Text: value})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(values)
+}
+
+// ServeHTTP serves the Grafana JSON API. The ad-hoc filters of a query are passed to the metric handlers through the
+// request's context.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == "/query" {
+		var err error
+		if r, err = withAdhocFilters(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	s.JSONServer.ServeHTTP(w, r)
+}
+
+type adhocFilter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// withAdhocFilters returns the request with the query's ad-hoc filters added to its context. The request body is
+// restored, so the query can be read again. Invalid JSON is left for the Grafana JSON server to report.
+func withAdhocFilters(r *http.Request) (*http.Request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return r, fmt.Errorf("read request: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var request struct {
+		AdhocFilters []adhocFilter `json:"adhocFilters"`
+	}
+	if err = json.Unmarshal(body, &request); err != nil || len(request.AdhocFilters) == 0 {
+		return r, nil
+	}
+	filter, err := parseAdhocFilters(request.AdhocFilters)
+	if err != nil {
+		return r, err
+	}
+	return r.WithContext(context.WithValue(r.Context(), filterKey{}, filter)), nil
+}
+
+func parseAdhocFilters(filters []adhocFilter) (sciensano.Filter, error) {
+	filter := make(sciensano.Filter, 0, len(filters))
+	for _, f := range filters {
+		tag, ok := sciensano.TagNames[f.Key]
+		if !ok {
+			return nil, fmt.Errorf("invalid filter key: %s", f.Key)
+		}
+		var negate bool
+		switch f.Operator {
+		case "=":
+		case "!=":
+			negate = true
+		default:
+			return nil, fmt.Errorf("invalid filter operator: %s", f.Operator)
+		}
+		filter = append(filter, sciensano.Condition{Tag: tag, Value: f.Value, Negate: negate})
+	}
+	return filter, nil
+}
+
+type filterKey struct{}
+
+// filterFromContext returns the ad-hoc filters of the query, or nil if the query has no filters.
+func filterFromContext(ctx context.Context) sciensano.Filter {
+	filter, _ := ctx.Value(filterKey{}).(sciensano.Filter)
+	return filter
+}
@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/clambin/go-common/tabulator"
+	grafanaJSONServer "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"github.com/clambin/sciensano/v2/internal/server/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_TagKeys(t *testing.T) {
+	s := New(mocks.NewReportsStore(t), config.Default(), nil, slog.Default())
+
+	req, _ := http.NewRequest(http.MethodPost, "/tag-keys", nil)
+	w := httptest.NewRecorder()
+	s.JSONServer.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `[{"type":"string","text":"region"},{"type":"string","text":"province"},{"type":"string","text":"ageGroup"},{"type":"string","text":"manufacturer"},{"type":"string","text":"dose"}]`+"\n", w.Body.String())
+}
+
+func TestServer_TagValues(t *testing.T) {
+	r := mocks.NewRecordsSource(t)
+	r.EXPECT().TagValues(sciensano.RegionTag).Return([]string{"Brussels", "Flanders", "Wallonia"})
+	s := New(mocks.NewReportsStore(t), config.Default(), nil, slog.Default())
+
+	testCases := []struct {
+		name     string
+		records  RecordsSource
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "valid",
+			records:  r,
+			body:     `{"key":"region"}`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"text":"Brussels"},{"text":"Flanders"},{"text":"Wallonia"}]` + "\n",
+		},
+		{
+			name:     "no records",
+			body:     `{"key":"region"}`,
+			wantCode: http.StatusOK,
+			wantBody: "[]\n",
+		},
+		{
+			name:     "invalid key",
+			records:  r,
+			body:     `{"key":"city"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid request",
+			records:  r,
+			body:     `{"key":`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s.Records = tt.records
+			req, _ := http.NewRequest(http.MethodPost, "/tag-values", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			s.JSONServer.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_withAdhocFilters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		body    string
+		wantErr assert.ErrorAssertionFunc
+		want    sciensano.Filter
+	}{
+		{
+			name:    "no filters",
+			body:    `{"targets":[]}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "filters",
+			body:    `{"targets":[],"adhocFilters":[{"key":"region","operator":"=","value":"Flanders"},{"key":"ageGroup","operator":"!=","value":"0-9"}]}`,
+			wantErr: assert.NoError,
+			want: sciensano.Filter{
+				{Tag: sciensano.RegionTag, Value: "Flanders"},
+				{Tag: sciensano.AgeGroupTag, Value: "0-9", Negate: true},
+			},
+		},
+		{
+			name:    "invalid json",
+			body:    `{"targets":`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid key",
+			body:    `{"adhocFilters":[{"key":"city","operator":"=","value":"Gent"}]}`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid operator",
+			body:    `{"adhocFilters":[{"key":"region","operator":"=~","value":"Fl.*"}]}`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/query", strings.NewReader(tt.body))
+			req, err := withAdhocFilters(req)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, filterFromContext(req.Context()))
+			// the body can still be read by the Grafana JSON server
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	s := New(mocks.NewReportsStore(t), config.Default(), nil, slog.Default())
+
+	req, _ := http.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"adhocFilters":[{"key":"city","operator":"=","value":"Gent"}]}`))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/tag-keys", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSummaryMetric_Query_Records(t *testing.T) {
+	stored := tabulator.New("Flanders", "Wallonia")
+	stored.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Flanders", 10)
+	stored.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Wallonia", 5)
+	filtered := tabulator.New("Flanders")
+	filtered.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Flanders", 4)
+	filter := sciensano.Filter{{Tag: sciensano.AgeGroupTag, Value: "0-9"}}
+	breakdown := tabulator.New("Flanders / 0-9", "Flanders / 10-19", "Wallonia / 0-9")
+	breakdown.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Flanders / 0-9", 4)
+
+	s := mocks.NewReportsStore(t)
+	s.EXPECT().Get("foo-ByRegion").Return(stored, nil).Maybe()
+	s.EXPECT().Get("foo-ByRegion-growth").Return(stored, nil).Maybe()
+	summarize := func(b sciensano.Breakdown, f sciensano.Filter) (*tabulator.Tabulator, error) {
+		switch {
+		case b.Secondary == sciensano.ByAgeGroup:
+			return breakdown, nil
+		case b.Secondary == sciensano.ByProvince:
+			// records not available
+			return nil, nil
+		case f[0].Value == "(none)":
+			// records not available
+			return nil, nil
+		case f[0].Value != "0-9":
+			return nil, errors.New("no data")
+		default:
+			return filtered, nil
+		}
+	}
+	_, query := newSummaryMetric(s, config.Metric{
+		Name:           "foo",
+		Type:           config.SummaryReport,
+		Modes:          []sciensano.SummaryColumn{sciensano.ByRegion},
+		Rates:          []config.Transform{config.Absolute, config.Growth},
+		Breakdowns:     []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByProvince, sciensano.ByAgeGroup},
+		ModeTransforms: map[sciensano.SummaryColumn][]config.Transform{sciensano.ByRegion: {config.Absolute, config.Growth}},
+	}, summarize)
+
+	testCases := []struct {
+		name        string
+		payload     string
+		filter      sciensano.Filter
+		wantErr     assert.ErrorAssertionFunc
+		wantColumns int
+	}{
+		{
+			name:        "no filter",
+			payload:     `{ "summary": "ByRegion", "accumulate": "no" }`,
+			wantErr:     assert.NoError,
+			wantColumns: 3,
+		},
+		{
+			name:        "filtered",
+			payload:     `{ "summary": "ByRegion", "accumulate": "no" }`,
+			filter:      filter,
+			wantErr:     assert.NoError,
+			wantColumns: 2,
+		},
+		{
+			name:    "filters are not supported for rates",
+			payload: `{ "summary": "ByRegion", "trend": "growth", "accumulate": "no" }`,
+			filter:  filter,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "filter not available",
+			payload: `{ "summary": "ByRegion", "accumulate": "no" }`,
+			filter:  sciensano.Filter{{Tag: sciensano.AgeGroupTag, Value: "(none)"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "filter failed",
+			payload: `{ "summary": "ByRegion", "accumulate": "no" }`,
+			filter:  sciensano.Filter{{Tag: sciensano.AgeGroupTag, Value: "90+"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:        "no breakdown",
+			payload:     `{ "summary": "ByRegion", "breakdown": "None", "accumulate": "no" }`,
+			wantErr:     assert.NoError,
+			wantColumns: 3,
+		},
+		{
+			name:        "breakdown",
+			payload:     `{ "summary": "ByRegion", "breakdown": "ByAgeGroup", "accumulate": "no" }`,
+			wantErr:     assert.NoError,
+			wantColumns: 4,
+		},
+		{
+			name:    "breakdown for rate",
+			payload: `{ "summary": "ByRegion", "breakdown": "ByAgeGroup", "trend": "growth", "accumulate": "no" }`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "breakdown not available",
+			payload: `{ "summary": "ByRegion", "breakdown": "ByProvince", "accumulate": "no" }`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "same breakdown as summary",
+			payload: `{ "summary": "ByRegion", "breakdown": "ByRegion", "accumulate": "no" }`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid breakdown",
+			payload: `{ "summary": "ByRegion", "breakdown": "ByManufacturer", "accumulate": "no" }`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.filter != nil {
+				ctx = context.WithValue(ctx, filterKey{}, tt.filter)
+			}
+			req := grafanaJSONServer.QueryRequest{Targets: []grafanaJSONServer.QueryRequestTarget{{Target: "foo", Payload: []byte(tt.payload)}}}
+			resp, err := query.Query(ctx, "foo", req)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Len(t, resp.(grafanaJSONServer.TableResponse).Columns, tt.wantColumns)
+		})
+	}
+}
+
+func TestQuery_FilterNotSupported(t *testing.T) {
+	// derived metrics aren't summarized from the records, so they can't be filtered
+	_, query := newSummaryMetric(mocks.NewReportsStore(t), config.Metric{
+		Name:           "rt",
+		Type:           config.RtReport,
+		Modes:          []sciensano.SummaryColumn{sciensano.Total},
+		ModeTransforms: map[sciensano.SummaryColumn][]config.Transform{sciensano.Total: {config.Absolute}},
+	}, nil)
+	_, vaccinationRate := newVaccinationDoseTypeMetric(mocks.NewReportsStore(t), "vaccination-rate", []sciensano.SummaryColumn{sciensano.ByRegion}, []sciensano.DoseType{sciensano.Full})
+
+	ctx := context.WithValue(context.Background(), filterKey{}, sciensano.Filter{{Tag: sciensano.RegionTag, Value: "Flanders"}})
+	req := grafanaJSONServer.QueryRequest{Targets: []grafanaJSONServer.QueryRequestTarget{{Target: "rt", Payload: []byte(`{ "summary": "Total" }`)}}}
+	_, err := query.Query(ctx, "rt", req)
+	assert.Error(t, err)
+
+	req = grafanaJSONServer.QueryRequest{Targets: []grafanaJSONServer.QueryRequestTarget{{Target: "vaccination-rate", Payload: []byte(`{ "summary": "ByRegion", "doseType": "Full", "accumulate": "no" }`)}}}
+	_, err = vaccinationRate.Query(ctx, "vaccination-rate", req)
+	assert.Error(t, err)
+}
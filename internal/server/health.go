@@ -2,10 +2,24 @@ package server
 
 import (
 	"encoding/json"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
 	"net/http"
 	"slices"
+	"time"
 )
 
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+)
+
+type sourceHealth struct {
+	datasource.Status
+	Stale bool
+}
+
+// Health reports the status of the server and its datasources. If any datasource is stale, the status is degraded
+// and Health responds with 503 Service Unavailable.
 func (s *Server) Health(w http.ResponseWriter, _ *http.Request) {
 	dataSources := make([]string, 0, len(s.Handlers))
 	for key := range s.Handlers {
@@ -13,13 +27,31 @@ func (s *Server) Health(w http.ResponseWriter, _ *http.Request) {
 	}
 	slices.Sort(dataSources)
 	response := struct {
+		Status        string
 		DataSources   []string
 		ReporterCache []string
+		Sources       []sourceHealth `json:",omitempty"`
 	}{
+		Status:        healthOK,
 		DataSources:   dataSources,
 		ReporterCache: s.reports.Keys(),
 	}
 
+	if s.Sources != nil {
+		now := time.Now()
+		for _, status := range s.Sources.GetStatus() {
+			stale := status.Stale(s.StalenessThreshold, now)
+			if stale {
+				response.Status = healthDegraded
+			}
+			response.Sources = append(response.Sources, sourceHealth{Status: status, Stale: stale})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status != healthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	_ = encoder.Encode(response)
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"encoding/json"
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
 	"github.com/clambin/sciensano/v2/internal/server/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -8,12 +11,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestServer_Health(t *testing.T) {
 	r := mocks.NewReportsStore(t)
 	r.EXPECT().Keys().Return([]string{"foo", "bar"})
-	s := New(r, nil, slog.Default())
+	s := New(r, config.Default(), nil, slog.Default())
 
 	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
@@ -21,10 +25,16 @@ func TestServer_Health(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, `{
+  "Status": "ok",
   "DataSources": [
+    "case-fatality-ratio",
     "cases",
+    "hospitalisation-ratio",
     "hospitalisations",
     "mortalities",
+    "municipality-cases",
+    "positivity-rate",
+    "rt",
     "tests",
     "vaccination-rate",
     "vaccinations"
@@ -36,3 +46,53 @@ func TestServer_Health(t *testing.T) {
 }
 `, w.Body.String())
 }
+
+func TestServer_Health_Stale(t *testing.T) {
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Keys().Return(nil)
+	sr := mocks.NewStatusReporter(t)
+	sr.EXPECT().GetStatus().Return([]datasource.Status{
+		{Name: "cases", LastPoll: time.Now(), LastModified: time.Now().Add(-time.Hour), Records: 10},
+		{Name: "vaccinations", LastPoll: time.Now().Add(-48 * time.Hour), LastModified: time.Now().Add(-48 * time.Hour), Records: 10},
+	})
+	s := New(r, config.Default(), nil, slog.Default())
+	s.Sources = sr
+	s.StalenessThreshold = 24 * time.Hour
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	s.JSONServer.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response struct {
+		Status  string
+		Sources []struct {
+			Name    string
+			Records int
+			Stale   bool
+		}
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "degraded", response.Status)
+	require.Len(t, response.Sources, 2)
+	assert.Equal(t, "cases", response.Sources[0].Name)
+	assert.Equal(t, 10, response.Sources[0].Records)
+	assert.False(t, response.Sources[0].Stale)
+	assert.True(t, response.Sources[1].Stale)
+}
+
+func TestServer_Health_NotPolled(t *testing.T) {
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Keys().Return(nil)
+	sr := mocks.NewStatusReporter(t)
+	sr.EXPECT().GetStatus().Return([]datasource.Status{{Name: "cases"}})
+	s := New(r, config.Default(), nil, slog.Default())
+	s.Sources = sr
+	s.StalenessThreshold = 24 * time.Hour
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	s.JSONServer.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
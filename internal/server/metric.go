@@ -2,18 +2,98 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/clambin/go-common/tabulator"
 	grafanaJSONServer "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
+	"slices"
+	"time"
 )
 
-func newSummaryMetric(s ReportsStore, name string, summaryColumns []sciensano.SummaryColumn) (grafanaJSONServer.Metric, grafanaJSONServer.Handler) {
+// recordsSummary summarizes the records that match an ad-hoc filter, as determined by the breakdown.
+// It returns nil if the records aren't available.
+type recordsSummary func(breakdown sciensano.Breakdown, filter sciensano.Filter) (*tabulator.Tabulator, error)
+
+// newSummaryMetric creates a metric for a summary. The metric's rates are the transforms of the summary that can be
+// requested, in addition to the absolute figures. Trends (growth and doubling time) are offered as a separate option
+// and can only be requested for the absolute figures. The rate or trend is appended to the summary's store key.
+// Requests for a summary mode and rate for which no report is generated are rejected.
+// If summarize is set, queries for the absolute figures with ad-hoc filters or a secondary breakdown summarize the
+// underlying records instead of returning the stored report. The metric's breakdowns are the summary columns that can
+// be requested as a secondary breakdown. Queries with ad-hoc filters for any other rate or trend are rejected.
+func newSummaryMetric(s ReportsStore, m config.Metric, summarize recordsSummary) (grafanaJSONServer.Metric, grafanaJSONServer.Handler) {
+	if summarize == nil {
+		m.Breakdowns = nil
+	}
+	breakdowns := m.Breakdowns
+	var rates, trends []string
+	for _, rate := range m.Rates {
+		if rate.IsTrend() {
+			trends = append(trends, string(rate))
+		} else {
+			rates = append(rates, string(rate))
+		}
+	}
 	var v []string
-	for _, value := range summaryColumns {
+	for _, value := range m.Modes {
 		v = append(v, value.String())
 	}
-	metric := makeMetric(name, []metricOption{{name: "Summary", values: v}}...)
-	return metric, handler{s: s, parseRequest: parseSummaryRequest}
+	options := []metricOption{{name: "Summary", values: v}}
+	if len(breakdowns) > 0 {
+		b := []string{noBreakdown}
+		for _, breakdown := range breakdowns {
+			b = append(b, breakdown.String())
+		}
+		options = append(options, metricOption{name: "Breakdown", values: b})
+	}
+	if len(rates) > 1 || len(rates) == 1 && rates[0] != string(config.Absolute) {
+		options = append(options, metricOption{name: "Rate", values: rates})
+	}
+	if len(trends) > 0 {
+		options = append(options, metricOption{name: "Trend", values: append([]string{noTrend}, trends...)})
+	}
+	metric := makeMetric(m.Name, accumulates(m.Type, config.Absolute), options...)
+	h := handler{
+		s: s,
+		parseRequest: func(target string, req grafanaJSONServer.QueryRequest) (string, queryOptions, error) {
+			return parseSummaryRequest(target, req, m)
+		},
+	}
+	if summarize != nil {
+		h.fromRecords = func(target string, req grafanaJSONServer.QueryRequest, filter sciensano.Filter) (*tabulator.Tabulator, error) {
+			return summarizeRecords(target, req, filter, m, summarize)
+		}
+	}
+	return metric, h
+}
+
+// summarizeRecords returns the report for a summary query with ad-hoc filters or a secondary breakdown. It returns nil
+// if the stored report can be used instead.
+func summarizeRecords(target string, req grafanaJSONServer.QueryRequest, filter sciensano.Filter, m config.Metric, summarize recordsSummary) (*tabulator.Tabulator, error) {
+	r, err := parseSummaryPayload(target, req, m)
+	if err != nil {
+		return nil, err
+	}
+	twoDimensional := r.breakdown.Secondary != sciensano.Total
+	if !twoDimensional && len(filter) == 0 {
+		return nil, nil
+	}
+	if r.rate != "" && r.rate != config.Absolute {
+		if twoDimensional {
+			return nil, fmt.Errorf("breakdown not supported for rate %s", r.rate)
+		}
+		return nil, fmt.Errorf("filter not supported for %s", r.rate)
+	}
+	records, err := summarize(r.breakdown, filter)
+	if err == nil && records == nil {
+		if twoDimensional {
+			return nil, errors.New("breakdown not available")
+		}
+		return nil, errors.New("filter not available")
+	}
+	return records, err
 }
 
 func newVaccinationDoseTypeMetric(s ReportsStore, name string, summaryColumns []sciensano.SummaryColumn, doseTypes []sciensano.DoseType) (grafanaJSONServer.Metric, grafanaJSONServer.Handler) {
@@ -25,7 +105,7 @@ func newVaccinationDoseTypeMetric(s ReportsStore, name string, summaryColumns []
 	for _, value := range doseTypes {
 		d = append(d, value.String())
 	}
-	metric := makeMetric(name, []metricOption{{name: "Summary", values: c}, {name: "DoseType", values: d}}...)
+	metric := makeMetric(name, true, []metricOption{{name: "Summary", values: c}, {name: "DoseType", values: d}}...)
 	return metric, handler{s: s, parseRequest: parseVaccinationDoseTypeRequest}
 }
 
@@ -34,7 +114,23 @@ type metricOption struct {
 	values []string
 }
 
-func makeMetric(name string, options ...metricOption) grafanaJSONServer.Metric {
+// accumulates reports whether the figures of a metric type, with the transform applied, can be accumulated. Counts and
+// counts per 100k inhabitants can, but accumulating a 14-day incidence, a trend, a positivity rate, an Rt estimate or
+// a ratio is meaningless.
+func accumulates(reportType config.ReportType, transform config.Transform) bool {
+	switch reportType {
+	case config.SummaryReport:
+		return transform == "" || transform == config.Absolute || transform == config.Per100k
+	case config.VaccinationRateReport:
+		return true
+	default:
+		return false
+	}
+}
+
+// makeMetric creates a metric with the options and the smoothing option. If accumulate is set, the metric also offers
+// the accumulate option.
+func makeMetric(name string, accumulate bool, options ...metricOption) grafanaJSONServer.Metric {
 	var payloads []grafanaJSONServer.MetricPayload
 	for _, option := range options {
 		var payloadOptions []grafanaJSONServer.MetricPayloadOption
@@ -54,98 +150,202 @@ func makeMetric(name string, options ...metricOption) grafanaJSONServer.Metric {
 		})
 	}
 	payloads = append(payloads, grafanaJSONServer.MetricPayload{
-		Label: "Accumulate",
-		Name:  "Accumulate",
+		Label: "Smoothing",
+		Name:  "Smoothing",
 		Type:  "select",
 		Width: 40,
 		Options: []grafanaJSONServer.MetricPayloadOption{
-			{Label: "Yes", Value: "yes"},
-			{Label: "No", Value: "no"},
+			{Label: "None", Value: noSmoothing.String()},
+			{Label: "7-day centred", Value: centred7DaySmoothing.String()},
+			{Label: "7-day trailing", Value: trailing7DaySmoothing.String()},
 		},
 	})
+	if accumulate {
+		payloads = append(payloads, grafanaJSONServer.MetricPayload{
+			Label: "Accumulate",
+			Name:  "Accumulate",
+			Type:  "select",
+			Width: 40,
+			Options: []grafanaJSONServer.MetricPayloadOption{
+				{Label: "Yes", Value: "yes"},
+				{Label: "No", Value: "no"},
+			},
+		})
+	}
 
 	return grafanaJSONServer.Metric{Value: name, Label: name, Payloads: payloads}
 }
 
 type handler struct {
 	s            ReportsStore
-	parseRequest func(string, grafanaJSONServer.QueryRequest) (string, bool, error)
+	parseRequest func(string, grafanaJSONServer.QueryRequest) (string, queryOptions, error)
+	// fromRecords returns the report for a query that can't be served from the stored reports, e.g. a query with ad-hoc
+	// filters. If it returns nil, the stored report is returned. If it's not set, queries with ad-hoc filters are rejected.
+	fromRecords func(string, grafanaJSONServer.QueryRequest, sciensano.Filter) (*tabulator.Tabulator, error)
 }
 
-func (h handler) Query(_ context.Context, target string, request grafanaJSONServer.QueryRequest) (grafanaJSONServer.QueryResponse, error) {
-	key, accumulate, err := h.parseRequest(target, request)
+// queryOptions determine how a report is transformed before it is returned
+type queryOptions struct {
+	accumulate bool
+	smoothing  smoothing
+}
+
+func (h handler) Query(ctx context.Context, target string, request grafanaJSONServer.QueryRequest) (grafanaJSONServer.QueryResponse, error) {
+	key, options, err := h.parseRequest(target, request)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get store key: %w", err)
 	}
 
-	records, err := h.s.Get(key)
-	if err != nil {
-		return nil, fmt.Errorf("fetch %s failed: %w", key, err)
+	var records *tabulator.Tabulator
+	filter := filterFromContext(ctx)
+	switch {
+	case h.fromRecords != nil:
+		if records, err = h.fromRecords(target, request, filter); err != nil {
+			return nil, fmt.Errorf("summarize %s failed: %w", key, err)
+		}
+	case len(filter) > 0:
+		return nil, fmt.Errorf("filter not supported for %s", target)
 	}
-	records = records.Copy()
-	if accumulate {
+	if records == nil {
+		if records, err = h.s.Get(key); err != nil {
+			return nil, fmt.Errorf("fetch %s failed: %w", key, err)
+		}
+	}
+	return createTableResponse(transform(records, options, request.Range.From, request.Range.To)), nil
+}
+
+// transform returns a copy of the report, smoothed and accumulated as requested, and limited to the requested time range.
+func transform(records *tabulator.Tabulator, options queryOptions, from, to time.Time) *tabulator.Tabulator {
+	records = smooth(records.Copy(), options.smoothing)
+	if options.accumulate {
 		records.Accumulate()
 	}
-	records.Filter(request.Range.From, request.Range.To)
-	return createTableResponse(records), nil
+	records.Filter(from, to)
+	return records
+}
+
+func parseSummaryRequest(target string, req grafanaJSONServer.QueryRequest, m config.Metric) (string, queryOptions, error) {
+	r, err := parseSummaryPayload(target, req, m)
+	if err != nil {
+		return "", r.options, err
+	}
+	return config.Report{Name: target}.Key(r.breakdown.Primary, r.rate), r.options, nil
+}
+
+// noBreakdown is the Breakdown option of a summary query that doesn't request a secondary breakdown.
+const noBreakdown = "None"
+
+// noTrend is the Trend option of a summary query that doesn't request a trend.
+const noTrend = "None"
+
+// summaryRequest holds the options of a summary query.
+type summaryRequest struct {
+	breakdown sciensano.Breakdown
+	rate      config.Transform
+	options   queryOptions
 }
 
-func parseSummaryRequest(target string, req grafanaJSONServer.QueryRequest) (string, bool, error) {
+func parseSummaryPayload(target string, req grafanaJSONServer.QueryRequest, m config.Metric) (summaryRequest, error) {
 	var summaryOption struct {
 		Summary    string
+		Breakdown  string
+		Rate       string
+		Trend      string
+		Smoothing  string
 		Accumulate string
 	}
-	var accumulate bool
+	var r summaryRequest
 	if err := req.GetPayload(target, &summaryOption); err != nil {
-		return "", accumulate, fmt.Errorf("invalid payload: %w", err)
+		return r, fmt.Errorf("invalid payload: %w", err)
 	}
 
 	//slog.Debug("getting request options", "row", string(req.Targets[0].Payload), "options", summaryOption)
 
-	mode, ok := sciensano.SummaryColumnNames[summaryOption.Summary]
-	if !ok {
-		return "", accumulate, fmt.Errorf("invalid summary option: %s", summaryOption.Summary)
+	var ok bool
+	if r.breakdown.Primary, ok = sciensano.SummaryColumnNames[summaryOption.Summary]; !ok {
+		return r, fmt.Errorf("invalid summary option: %s", summaryOption.Summary)
 	}
-	switch summaryOption.Accumulate {
-	case "yes":
-		accumulate = true
-	case "no":
-		accumulate = false
-	default:
-		return "", accumulate, fmt.Errorf("invalid accumulate value: %s", summaryOption.Accumulate)
+	// dashboards created before breakdowns were supported don't send a breakdown option
+	if summaryOption.Breakdown != "" && summaryOption.Breakdown != noBreakdown {
+		r.breakdown.Secondary, ok = sciensano.SummaryColumnNames[summaryOption.Breakdown]
+		if !ok || !slices.Contains(m.Breakdowns, r.breakdown.Secondary) || r.breakdown.Secondary == r.breakdown.Primary {
+			return r, fmt.Errorf("invalid breakdown option: %s", summaryOption.Breakdown)
+		}
 	}
-
-	return target + "-" + mode.String(), accumulate, nil
+	r.rate = config.Transform(summaryOption.Rate)
+	if r.rate != "" && r.rate != config.Absolute && (r.rate.IsTrend() || !slices.Contains(m.Rates, r.rate)) {
+		return r, fmt.Errorf("invalid rate option: %s", summaryOption.Rate)
+	}
+	// dashboards created before trends were supported don't send a trend option
+	trend := summaryOption.Trend != "" && summaryOption.Trend != noTrend
+	if trend {
+		t := config.Transform(summaryOption.Trend)
+		if !t.IsTrend() || !slices.Contains(m.Rates, t) {
+			return r, fmt.Errorf("invalid trend option: %s", summaryOption.Trend)
+		}
+		if r.rate != "" && r.rate != config.Absolute {
+			return r, fmt.Errorf("trend %s not available for rate %s", t, r.rate)
+		}
+		r.rate = t
+	}
+	// reports with the same name are combined into one metric, so not every rate is generated for every summary mode
+	if !m.SupportsTransform(r.breakdown.Primary, r.rate) {
+		rate := r.rate
+		if rate == "" {
+			rate = config.Absolute
+		}
+		return r, fmt.Errorf("rate %s not available for summary %s", rate, r.breakdown.Primary)
+	}
+	var err error
+	r.options, err = parseQueryOptions(summaryOption.Accumulate, summaryOption.Smoothing, accumulates(m.Type, r.rate))
+	return r, err
 }
 
-func parseVaccinationDoseTypeRequest(target string, req grafanaJSONServer.QueryRequest) (string, bool, error) {
+func parseVaccinationDoseTypeRequest(target string, req grafanaJSONServer.QueryRequest) (string, queryOptions, error) {
 	var summaryOption struct {
 		Summary    string
 		DoseType   string
+		Smoothing  string
 		Accumulate string
 	}
-	var accumulate bool
+	var options queryOptions
 	if err := req.GetPayload(target, &summaryOption); err != nil {
-		return "", accumulate, fmt.Errorf("invalid payload: %w", err)
+		return "", options, fmt.Errorf("invalid payload: %w", err)
 	}
 
 	mode, ok := sciensano.SummaryColumnNames[summaryOption.Summary]
 	if !ok {
-		return "", accumulate, fmt.Errorf("invalid summary option: %s", summaryOption.Summary)
+		return "", options, fmt.Errorf("invalid summary option: %s", summaryOption.Summary)
 	}
 
 	if _, ok = sciensano.DoseTypeNames[summaryOption.DoseType]; !ok {
-		return "", accumulate, fmt.Errorf("invalid dose type: %s", summaryOption.DoseType)
+		return "", options, fmt.Errorf("invalid dose type: %s", summaryOption.DoseType)
 	}
 
-	switch summaryOption.Accumulate {
-	case "yes":
-		accumulate = true
-	case "no":
-		accumulate = false
+	options, err := parseQueryOptions(summaryOption.Accumulate, summaryOption.Smoothing, true)
+	return target + "-" + summaryOption.DoseType + "-" + mode.String(), options, err
+}
+
+// parseQueryOptions parses the accumulate and smoothing options of a query. If canAccumulate is false, the metric doesn't
+// offer the accumulate option: the option may be missing, and any dashboards that still request accumulation get an error.
+func parseQueryOptions(accumulate, smoothingOption string, canAccumulate bool) (queryOptions, error) {
+	var options queryOptions
+	switch {
+	case accumulate == "yes" && canAccumulate:
+		options.accumulate = true
+	case accumulate == "yes":
+		return options, errors.New("accumulate not supported")
+	case accumulate == "no", accumulate == "" && !canAccumulate:
 	default:
-		return "", accumulate, fmt.Errorf("invalid accumulate value: %s", summaryOption.Accumulate)
+		return options, fmt.Errorf("invalid accumulate value: %s", accumulate)
 	}
 
-	return target + "-" + summaryOption.DoseType + "-" + mode.String(), accumulate, nil
+	// dashboards created before smoothing was supported don't send a smoothing option
+	if smoothingOption != "" {
+		var ok bool
+		if options.smoothing, ok = smoothingNames[smoothingOption]; !ok {
+			return options, fmt.Errorf("invalid smoothing value: %s", smoothingOption)
+		}
+	}
+	return options, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/clambin/go-common/tabulator"
 	grafanaJSONServer "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/server/mocks"
 	"github.com/stretchr/testify/assert"
@@ -12,22 +13,115 @@ import (
 )
 
 func TestNewSummaryMetric(t *testing.T) {
-	metric, _ := newSummaryMetric(nil, "foo", []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup})
+	modes := []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup}
+	metric, _ := newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.SummaryReport, Modes: modes}, nil)
 
 	assert.Equal(t, "foo", metric.Label)
 	assert.Equal(t, "foo", metric.Value)
-	require.Len(t, metric.Payloads, 2)
+	require.Len(t, metric.Payloads, 3)
 	assert.Equal(t, "Summary", metric.Payloads[0].Name)
 	assert.Len(t, metric.Payloads[0].Options, 2)
-	assert.Equal(t, "Accumulate", metric.Payloads[1].Name)
-	assert.Len(t, metric.Payloads[1].Options, 2)
+	assert.Equal(t, "Smoothing", metric.Payloads[1].Name)
+	assert.Len(t, metric.Payloads[1].Options, 3)
+	assert.Equal(t, "Accumulate", metric.Payloads[2].Name)
+	assert.Len(t, metric.Payloads[2].Options, 2)
+
+	metric, _ = newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.SummaryReport, Modes: modes, Rates: []config.Transform{config.Absolute, config.Per100k, config.Per100k14d, config.Growth, config.DoublingTime}}, nil)
+	require.Len(t, metric.Payloads, 5)
+	assert.Equal(t, "Rate", metric.Payloads[1].Name)
+	assert.Len(t, metric.Payloads[1].Options, 3)
+	assert.Equal(t, "Trend", metric.Payloads[2].Name)
+	assert.Equal(t, []grafanaJSONServer.MetricPayloadOption{
+		{Label: "None", Value: "None"},
+		{Label: "growth", Value: "growth"},
+		{Label: "doubling-time", Value: "doubling-time"},
+	}, metric.Payloads[2].Options)
+
+	// no rate option if only trends are generated
+	metric, _ = newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.SummaryReport, Modes: modes, Rates: []config.Transform{config.Absolute, config.Growth}}, nil)
+	require.Len(t, metric.Payloads, 4)
+	assert.Equal(t, "Trend", metric.Payloads[1].Name)
+
+	summarize := func(sciensano.Breakdown, sciensano.Filter) (*tabulator.Tabulator, error) { return nil, nil }
+	metric, _ = newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.SummaryReport, Modes: modes, Breakdowns: modes}, summarize)
+	require.Len(t, metric.Payloads, 4)
+	assert.Equal(t, "Breakdown", metric.Payloads[1].Name)
+	assert.Equal(t, []grafanaJSONServer.MetricPayloadOption{
+		{Label: "None", Value: "None"},
+		{Label: "ByRegion", Value: "ByRegion"},
+		{Label: "ByAgeGroup", Value: "ByAgeGroup"},
+	}, metric.Payloads[1].Options)
+
+	// breakdowns require the underlying records
+	metric, _ = newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.SummaryReport, Modes: modes, Breakdowns: modes}, nil)
+	require.Len(t, metric.Payloads, 3)
+
+	// rates and ratios can't be accumulated
+	metric, _ = newSummaryMetric(nil, config.Metric{Name: "foo", Type: config.RtReport, Modes: modes}, nil)
+	require.Len(t, metric.Payloads, 2)
+	assert.Equal(t, "Smoothing", metric.Payloads[1].Name)
+}
+
+func TestSummaryMetric_Query_Accumulate(t *testing.T) {
+	s := mocks.NewReportsStore(t)
+	table := tabulator.New("A")
+	s.EXPECT().Get("foo-Total").Return(table, nil)
+	_, query := newSummaryMetric(s, config.Metric{
+		Name:           "foo",
+		Type:           config.RtReport,
+		Modes:          []sciensano.SummaryColumn{sciensano.Total},
+		ModeTransforms: map[sciensano.SummaryColumn][]config.Transform{sciensano.Total: {config.Absolute}},
+	}, nil)
+
+	for payload, wantErr := range map[string]assert.ErrorAssertionFunc{
+		`{ "summary": "Total" }`:                      assert.NoError,
+		`{ "summary": "Total", "accumulate": "no" }`:  assert.NoError,
+		`{ "summary": "Total", "accumulate": "yes" }`: assert.Error,
+	} {
+		req := grafanaJSONServer.QueryRequest{Targets: []grafanaJSONServer.QueryRequestTarget{{Payload: []byte(payload), Target: "foo"}}}
+		_, err := query.Query(context.Background(), "foo", req)
+		wantErr(t, err, payload)
+	}
+}
+
+func TestAccumulates(t *testing.T) {
+	for _, tt := range []struct {
+		reportType config.ReportType
+		transform  config.Transform
+		want       bool
+	}{
+		{reportType: config.SummaryReport, want: true},
+		{reportType: config.SummaryReport, transform: config.Absolute, want: true},
+		{reportType: config.SummaryReport, transform: config.Per100k, want: true},
+		{reportType: config.SummaryReport, transform: config.Per100k14d, want: false},
+		{reportType: config.SummaryReport, transform: config.PerHospital, want: false},
+		{reportType: config.SummaryReport, transform: config.Growth, want: false},
+		{reportType: config.SummaryReport, transform: config.DoublingTime, want: false},
+		{reportType: config.VaccinationRateReport, want: true},
+		{reportType: config.RtReport, want: false},
+	} {
+		assert.Equal(t, tt.want, accumulates(tt.reportType, tt.transform), string(tt.reportType)+" "+string(tt.transform))
+	}
 }
 
 func TestSummaryMetric_Query(t *testing.T) {
 	s := mocks.NewReportsStore(t)
 	table := tabulator.New("A", "B")
 	s.EXPECT().Get("foo-ByRegion").Return(table, nil)
-	_, query := newSummaryMetric(s, "foo", []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup})
+	s.EXPECT().Get("foo-ByRegion-per100k").Return(table, nil)
+	s.EXPECT().Get("foo-ByRegion-per100k-14d").Return(table, nil)
+	s.EXPECT().Get("foo-ByRegion-growth").Return(table, nil)
+	_, query := newSummaryMetric(s, config.Metric{
+		Name:  "foo",
+		Type:  config.SummaryReport,
+		Modes: []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup, sciensano.ByGender},
+		Rates: []config.Transform{config.Absolute, config.Per100k, config.Per100k14d, config.Growth, config.DoublingTime},
+		ModeTransforms: map[sciensano.SummaryColumn][]config.Transform{
+			sciensano.ByRegion:   {config.Absolute, config.Per100k, config.Per100k14d, config.Growth, config.DoublingTime},
+			sciensano.ByAgeGroup: {config.Absolute, config.Per100k, config.Per100k14d, config.Growth, config.DoublingTime},
+			sciensano.ByGender:   {config.Per100k},
+		},
+	}, nil)
 
 	ctx := context.Background()
 
@@ -41,6 +135,86 @@ func TestSummaryMetric_Query(t *testing.T) {
 			payload: []byte(`{ "summary": "ByRegion", "accumulate": "no" }`),
 			wantErr: assert.NoError,
 		},
+		{
+			name:    "smoothed",
+			payload: []byte(`{ "summary": "ByRegion", "smoothing": "7d-centred", "accumulate": "no" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per100k-14d", "accumulate": "no" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "trend",
+			payload: []byte(`{ "summary": "ByRegion", "trend": "growth", "accumulate": "no" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "trend of absolute figures",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "absolute", "trend": "growth", "accumulate": "no" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "trend of a rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per100k", "trend": "growth", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "trend as rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "growth", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid trend",
+			payload: []byte(`{ "summary": "ByRegion", "trend": "per100k", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "accumulated",
+			payload: []byte(`{ "summary": "ByRegion", "accumulate": "yes" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "accumulated rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per100k", "accumulate": "yes" }`),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "accumulated 14-day incidence",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per100k-14d", "accumulate": "yes" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "accumulated trend",
+			payload: []byte(`{ "summary": "ByRegion", "trend": "growth", "accumulate": "yes" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "rate not generated for summary",
+			payload: []byte(`{ "summary": "ByGender", "rate": "per100k-14d", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "absolute figures not generated for summary",
+			payload: []byte(`{ "summary": "ByGender", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unsupported rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per-hospital", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid rate",
+			payload: []byte(`{ "summary": "ByRegion", "rate": "per1M", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid smoothing",
+			payload: []byte(`{ "summary": "ByRegion", "smoothing": "14d", "accumulate": "no" }`),
+			wantErr: assert.Error,
+		},
 		{
 			name:    "invalid accumulate",
 			payload: []byte(`{ "summary": "ByRegion", "accumulate": "false" }`),
@@ -81,13 +255,15 @@ func TestNewVaccinationDoseTypeMetric(t *testing.T) {
 
 	assert.Equal(t, "foo", metric.Label)
 	assert.Equal(t, "foo", metric.Value)
-	require.Len(t, metric.Payloads, 3)
+	require.Len(t, metric.Payloads, 4)
 	assert.Equal(t, "Summary", metric.Payloads[0].Name)
 	assert.Len(t, metric.Payloads[0].Options, 2)
 	assert.Equal(t, "DoseType", metric.Payloads[1].Name)
 	assert.Len(t, metric.Payloads[1].Options, 1)
-	assert.Equal(t, "Accumulate", metric.Payloads[2].Name)
-	assert.Len(t, metric.Payloads[2].Options, 2)
+	assert.Equal(t, "Smoothing", metric.Payloads[2].Name)
+	assert.Len(t, metric.Payloads[2].Options, 3)
+	assert.Equal(t, "Accumulate", metric.Payloads[3].Name)
+	assert.Len(t, metric.Payloads[3].Options, 2)
 }
 
 func TestVaccinationDoseTypeMetric_Query(t *testing.T) {
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// ReadinessChecker reports whether a component has loaded its data
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+type probeResponse struct {
+	Status  string
+	Missing *missing `json:",omitempty"`
+}
+
+type missing struct {
+	Population  bool     `json:",omitempty"`
+	DataSources []string `json:",omitempty"`
+	Reports     []string `json:",omitempty"`
+}
+
+// Live reports whether the server is able to serve requests. It doesn't depend on upstream data being available,
+// but it fails to respond if the reports store or the datasources are deadlocked.
+func (s *Server) Live(w http.ResponseWriter, _ *http.Request) {
+	_ = s.reports.Keys()
+	if s.Sources != nil {
+		_ = s.Sources.GetStatus()
+	}
+	writeProbeResponse(w, http.StatusOK, probeResponse{Status: "live"})
+}
+
+// Ready reports whether the server has all the data it needs to serve requests: the population data has loaded,
+// every datasource has data and every reporter has stored its report. If not, it reports what is missing.
+func (s *Server) Ready(w http.ResponseWriter, _ *http.Request) {
+	var m missing
+	if s.Population != nil && !s.Population.IsReady() {
+		m.Population = true
+	}
+	if s.Sources != nil {
+		for _, status := range s.Sources.GetStatus() {
+			if status.LastModified.IsZero() {
+				m.DataSources = append(m.DataSources, status.Name)
+			}
+		}
+	}
+	keys := s.reports.Keys()
+	for _, report := range s.ExpectedReports {
+		if !slices.Contains(keys, report) {
+			m.Reports = append(m.Reports, report)
+		}
+	}
+
+	if !m.Population && len(m.DataSources) == 0 && len(m.Reports) == 0 {
+		writeProbeResponse(w, http.StatusOK, probeResponse{Status: "ready"})
+		return
+	}
+	writeProbeResponse(w, http.StatusServiceUnavailable, probeResponse{Status: "not ready", Missing: &m})
+}
+
+func writeProbeResponse(w http.ResponseWriter, statusCode int, response probeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(response)
+}
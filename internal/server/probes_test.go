@@ -0,0 +1,100 @@
+package server
+
+import (
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
+	"github.com/clambin/sciensano/v2/internal/server/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_Live(t *testing.T) {
+	r := mocks.NewReportsStore(t)
+	r.EXPECT().Keys().Return(nil)
+	s := New(r, config.Default(), nil, slog.Default())
+
+	req, _ := http.NewRequest(http.MethodGet, "/livez", nil)
+	w := httptest.NewRecorder()
+	s.JSONServer.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{
+  "Status": "live"
+}
+`, w.Body.String())
+}
+
+type fakeReadinessChecker bool
+
+func (f fakeReadinessChecker) IsReady() bool {
+	return bool(f)
+}
+
+func TestServer_Ready(t *testing.T) {
+	testCases := []struct {
+		name       string
+		population bool
+		status     []datasource.Status
+		keys       []string
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "ready",
+			population: true,
+			status:     []datasource.Status{{Name: "cases", LastModified: time.Now()}},
+			keys:       []string{"cases-Total", "cases-ByRegion"},
+			wantCode:   http.StatusOK,
+			wantBody: `{
+  "Status": "ready"
+}
+`,
+		},
+		{
+			name:       "not ready",
+			population: false,
+			status:     []datasource.Status{{Name: "cases", LastModified: time.Now()}, {Name: "vaccinations"}},
+			keys:       []string{"cases-Total"},
+			wantCode:   http.StatusServiceUnavailable,
+			wantBody: `{
+  "Status": "not ready",
+  "Missing": {
+    "Population": true,
+    "DataSources": [
+      "vaccinations"
+    ],
+    "Reports": [
+      "cases-ByRegion"
+    ]
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewReportsStore(t)
+			r.EXPECT().Keys().Return(tt.keys)
+			sr := mocks.NewStatusReporter(t)
+			sr.EXPECT().GetStatus().Return(tt.status)
+
+			s := New(r, config.Default(), nil, slog.Default())
+			s.Sources = sr
+			s.Population = fakeReadinessChecker(tt.population)
+			s.ExpectedReports = []string{"cases-Total", "cases-ByRegion"}
+
+			req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			s.JSONServer.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -2,18 +2,35 @@ package server
 
 import (
 	"context"
-	"github.com/clambin/go-common/set"
 	"github.com/clambin/go-common/tabulator"
 	gjson "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
+	"github.com/clambin/sciensano/v2/internal/reports/datasource"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"log/slog"
+	"time"
 )
 
 // Server groups Grafana JSON API handlers that retrieve Belgium COVID-19-related statistics
 type Server struct {
 	JSONServer *gjson.Server
 	Handlers   map[string]gjson.Handler
-	reports    ReportsStore
+	// Sources reports the freshness of the datasources. If set, /health reports when any datasource is stale.
+	Sources StatusReporter
+	// StalenessThreshold is the age after which a datasource is considered stale. Zero disables the check.
+	StalenessThreshold time.Duration
+	// Population reports whether the population data has loaded. If set, /readyz waits for it.
+	Population ReadinessChecker
+	// Records gives access to the records underlying the reports. If set, queries can use ad-hoc filters and secondary breakdowns.
+	Records RecordsSource
+	// Updates reports when the datasources published new data. If set, /annotations shows each update.
+	Updates UpdateReporter
+	// Events are user-maintained annotations (e.g. lockdowns) that /annotations shows on top of the datasource updates.
+	Events []Event
+	// ExpectedReports are the reports that must be stored before /readyz reports the server as ready.
+	ExpectedReports []string
+	reports         ReportsStore
+	logger          *slog.Logger
 }
 
 type ReportsStore interface {
@@ -21,10 +38,16 @@ type ReportsStore interface {
 	Keys() []string
 }
 
-func New(reportsStore ReportsStore, metrics gjson.PrometheusQueryMetrics, logger *slog.Logger) *Server {
+type StatusReporter interface {
+	GetStatus() []datasource.Status
+}
+
+// New creates a Server that offers the reports in the configuration as Grafana metrics. The configuration must be valid.
+func New(reportsStore ReportsStore, cfg config.Config, metrics gjson.PrometheusQueryMetrics, logger *slog.Logger) *Server {
 	s := &Server{
 		Handlers: make(map[string]gjson.Handler),
 		reports:  reportsStore,
+		logger:   logger,
 	}
 
 	options := []gjson.Option{
@@ -32,34 +55,45 @@ func New(reportsStore ReportsStore, metrics gjson.PrometheusQueryMetrics, logger
 		gjson.WithPrometheusQueryMetrics(metrics),
 	}
 
-	summaryHandlers := []struct {
-		name           string
-		summaryColumns set.Set[sciensano.SummaryColumn]
-		accumulate     bool
-	}{
-		{name: "cases", summaryColumns: sciensano.CasesValidSummaryModes()},
-		{name: "hospitalisations", summaryColumns: sciensano.HospitalisationsValidSummaryModes()},
-		{name: "mortalities", summaryColumns: sciensano.MortalitiesValidSummaryModes()},
-		{name: "tests", summaryColumns: sciensano.TestResultsValidSummaryModes()},
-		{name: "vaccinations", summaryColumns: sciensano.VaccinationsValidSummaryModes(), accumulate: true},
-	}
-
-	for _, summaryHandler := range summaryHandlers {
-		metric, h := newSummaryMetric(reportsStore, summaryHandler.name, summaryHandler.summaryColumns.List())
-
-		s.Handlers[summaryHandler.name] = h
+	for _, m := range cfg.Metrics() {
+		var metric gjson.Metric
+		var h gjson.Handler
+		switch m.Type {
+		case config.VaccinationRateReport:
+			metric, h = newVaccinationDoseTypeMetric(reportsStore, m.Name, m.Modes, m.DoseTypes)
+		default:
+			metric, h = newSummaryMetric(reportsStore, m, s.recordsSummary(m.Datasource))
+		}
+		s.Handlers[m.Name] = h
 		options = append(options, gjson.WithMetric(metric, h, nil))
 	}
 
-	metric, h := newVaccinationDoseTypeMetric(reportsStore, "vaccination-rate", []sciensano.SummaryColumn{sciensano.ByRegion, sciensano.ByAgeGroup}, []sciensano.DoseType{sciensano.Partial, sciensano.Full})
-	s.Handlers[metric.Value] = h
-	options = append(options, gjson.WithMetric(metric, h, nil))
-
 	s.JSONServer = gjson.NewServer(options...)
 	s.JSONServer.HandleFunc("/health", s.Health)
+	s.JSONServer.HandleFunc("/livez", s.Live)
+	s.JSONServer.HandleFunc("/readyz", s.Ready)
+	s.JSONServer.HandleFunc("/api/reports", s.ListReports)
+	s.JSONServer.HandleFunc("/api/report", s.ExportReport)
+	s.JSONServer.HandleFunc("/annotations", s.Annotations)
+	s.JSONServer.HandleFunc("/tag-keys", s.TagKeys)
+	s.JSONServer.HandleFunc("/tag-values", s.TagValues)
 	return s
 }
 
+// recordsSummary returns a function that summarizes the records of the datasource, or nil if the metric isn't based
+// on a datasource.
+func (s *Server) recordsSummary(datasource string) recordsSummary {
+	if datasource == "" {
+		return nil
+	}
+	return func(breakdown sciensano.Breakdown, filter sciensano.Filter) (*tabulator.Tabulator, error) {
+		if s.Records == nil {
+			return nil, nil
+		}
+		return s.Records.Summarize(datasource, breakdown, filter)
+	}
+}
+
 // Run starts the supporting components
 func (s *Server) Run(ctx context.Context) error {
 	<-ctx.Done()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/clambin/go-common/tabulator"
 	gjson "github.com/clambin/grafana-json-server"
+	"github.com/clambin/sciensano/v2/internal/config"
 	"github.com/clambin/sciensano/v2/internal/sciensano"
 	"github.com/clambin/sciensano/v2/internal/sciensano/testutil"
 	"github.com/clambin/sciensano/v2/internal/server"
@@ -18,12 +19,22 @@ import (
 
 func TestNew(t *testing.T) {
 	store := makeStore(t)
-	s := server.New(store, nil, slog.Default())
+	cfg := config.Default()
+	s := server.New(store, cfg, nil, slog.Default())
 	ctx := context.Background()
 
+	// only counts can be accumulated
+	accumulate := make(map[string]string)
+	for _, report := range cfg.Reports {
+		accumulate[report.Name] = "no"
+		if reportType := report.GetType(); reportType == config.SummaryReport || reportType == config.VaccinationRateReport {
+			accumulate[report.Name] = "yes"
+		}
+	}
+
 	for target, handler := range s.Handlers {
 		t.Run(target, func(t *testing.T) {
-			payload := fmt.Sprintf(`{"summary":"%s", "accumulate": "yes"}`, sciensano.Total.String())
+			payload := fmt.Sprintf(`{"summary":"%s", "accumulate": "%s"}`, sciensano.Total.String(), accumulate[target])
 			if target == "vaccination-rate" {
 				payload = fmt.Sprintf(`{"summary":"%s", "doseType": "%s", "accumulate": "yes"}`, sciensano.Total.String(), sciensano.Partial.String())
 			}
@@ -55,5 +66,16 @@ func makeStore(t *testing.T) server.ReportsStore {
 	vaccinations, _ := testutil.Vaccinations().Summarize(sciensano.Total)
 	s.EXPECT().Get("vaccinations-Total").Return(vaccinations, nil)
 	s.EXPECT().Get("vaccination-rate-Partial-Total").Return(tabulator.New(), nil)
+	municipalityCases, _ := testutil.MunicipalityCases().Summarize(sciensano.Total)
+	s.EXPECT().Get("municipality-cases-Total").Return(municipalityCases, nil)
+	positivityRate, _ := testutil.TestResults().PositivityRate(sciensano.Total, 7)
+	s.EXPECT().Get("positivity-rate-Total").Return(positivityRate, nil)
+	rt := tabulator.New("Total", "Total (low)", "Total (high)")
+	rt.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Total", 1.1)
+	s.EXPECT().Get("rt-Total").Return(rt, nil)
+	ratio := tabulator.New("Total")
+	ratio.Set(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Total", 0.01)
+	s.EXPECT().Get("case-fatality-ratio-Total").Return(ratio, nil)
+	s.EXPECT().Get("hospitalisation-ratio-Total").Return(ratio, nil)
 	return s
 }
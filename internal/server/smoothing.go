@@ -0,0 +1,88 @@
+package server
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"time"
+)
+
+// smoothing determines how daily values are averaged to remove the weekday effect
+type smoothing int
+
+const (
+	noSmoothing smoothing = iota
+	centred7DaySmoothing
+	trailing7DaySmoothing
+)
+
+var smoothingStrings = map[smoothing]string{
+	noSmoothing:           "none",
+	centred7DaySmoothing:  "7d-centred",
+	trailing7DaySmoothing: "7d-trailing",
+}
+
+var smoothingNames = map[string]smoothing{
+	"none":        noSmoothing,
+	"7d-centred":  centred7DaySmoothing,
+	"7d-trailing": trailing7DaySmoothing,
+}
+
+func (s smoothing) String() string {
+	return smoothingStrings[s]
+}
+
+// window returns the number of days before and after a day that are averaged into the smoothed value for that day
+func (s smoothing) window() (int, int) {
+	switch s {
+	case centred7DaySmoothing:
+		return 3, 3
+	case trailing7DaySmoothing:
+		return 6, 0
+	default:
+		return 0, 0
+	}
+}
+
+// smooth replaces each value by the average of the values in the window around its day. Days without a value count as zero.
+// At the edges of the table, only the days within the table are averaged.
+func smooth(t *tabulator.Tabulator, s smoothing) *tabulator.Tabulator {
+	before, after := s.window()
+	if before == 0 && after == 0 {
+		return t
+	}
+
+	timestamps := t.GetTimestamps()
+	if len(timestamps) == 0 {
+		return t
+	}
+	first, last := timestamps[0], timestamps[len(timestamps)-1]
+
+	columns := t.GetColumns()
+	smoothed := tabulator.New(columns...)
+	for _, column := range columns {
+		values, _ := t.GetValues(column)
+		for i, timestamp := range timestamps {
+			from, to := timestamp.AddDate(0, 0, -before), timestamp.AddDate(0, 0, after)
+			if from.Before(first) {
+				from = first
+			}
+			if to.After(last) {
+				to = last
+			}
+
+			var total float64
+			for j := i; j >= 0 && !timestamps[j].Before(from); j-- {
+				total += values[j]
+			}
+			for j := i + 1; j < len(timestamps) && !timestamps[j].After(to); j++ {
+				total += values[j]
+			}
+			smoothed.Set(timestamp, column, total/(float64(days(from, to))))
+		}
+	}
+	return smoothed
+}
+
+// days returns the number of days from from to to, inclusive
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Round(24*time.Hour)/(24*time.Hour)) + 1
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"github.com/clambin/go-common/tabulator"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSmooth(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	table := tabulator.New("A")
+	for i, value := range []float64{7, 14, 7, 14, 7, 14, 7, 14, 7, 14} {
+		// no data for day 5: counts as zero
+		if i != 5 {
+			table.Set(start.AddDate(0, 0, i), "A", value)
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		smoothing smoothing
+		want      []float64
+	}{
+		{
+			name:      "none",
+			smoothing: noSmoothing,
+			want:      []float64{7, 14, 7, 14, 7, 7, 14, 7, 14},
+		},
+		{
+			name:      "centred",
+			smoothing: centred7DaySmoothing,
+			want:      []float64{10.5, 9.8, 8.166666666666666, 8, 9, 9, 8.166666666666666, 8.4, 10.5},
+		},
+		{
+			name:      "trailing",
+			smoothing: trailing7DaySmoothing,
+			want:      []float64{7, 10.5, 9.333333333333334, 10.5, 9.8, 8, 9, 8, 9},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			smoothed := smooth(table.Copy(), tt.smoothing)
+			assert.Equal(t, table.GetTimestamps(), smoothed.GetTimestamps())
+			values, ok := smoothed.GetValues("A")
+			assert.True(t, ok)
+			assert.InDeltaSlice(t, tt.want, values, 0.0001)
+		})
+	}
+}